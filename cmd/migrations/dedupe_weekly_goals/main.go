@@ -0,0 +1,53 @@
+package main
+
+import (
+	"log"
+
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/config"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// Removes duplicate weekly goals so the unique (user_id, week_start_date, goal_type)
+// index can be created. Run it before starting a server with that index.
+// Per user, week and type it keeps the goal with the most progress, then the oldest.
+// Usage: go run cmd/migrations/dedupe_weekly_goals/main.go
+func main() {
+	cfg := config.LoadConfig()
+
+	// Connect without repository.InitDB: its AutoMigrate fails while duplicates exist
+	db, err := gorm.Open(postgres.Open(cfg.DatabaseURL), &gorm.Config{})
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Exec(`
+			DELETE FROM weekly_goals
+			WHERE id IN (
+				SELECT id FROM (
+					SELECT id, ROW_NUMBER() OVER (
+						PARTITION BY user_id, week_start_date, goal_type
+						ORDER BY completion_percent DESC, created_at, id
+					) AS rank
+					FROM weekly_goals
+				) ranked
+				WHERE rank > 1
+			);
+		`)
+		if res.Error != nil {
+			return res.Error
+		}
+		log.Printf("Removed %d duplicate weekly goals", res.RowsAffected)
+
+		return tx.Exec(`
+			CREATE UNIQUE INDEX IF NOT EXISTS idx_weekly_goals_user_week_type
+			ON weekly_goals (user_id, week_start_date, goal_type);
+		`).Error
+	})
+	if err != nil {
+		log.Fatalf("Failed to dedupe weekly goals: %v", err)
+	}
+
+	log.Println("Successfully added the unique weekly goal index")
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/config"
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/models"
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/repository"
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/services"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+const batchSize = 500
+
+type goalRow struct {
+	ID               uuid.UUID
+	UserID           uuid.UUID
+	WeekStartDate    time.Time
+	SelectedProblems datatypes.JSON
+}
+
+// Rewrites selected_problems of weekly goals and their archive from the legacy
+// {"Monday": [...]} maps to the versioned problem set. Problems stored as plain titles
+// get their slug from the user's solved problems when it is known there. Fields older
+// rows never stored (frontend id, topics, acceptance rate) stay empty.
+// Run it after week_start_dates. It only touches rows without a version, so it is safe to run twice.
+// Usage: go run cmd/migrations/goal_problem_sets/main.go
+func main() {
+	cfg := config.LoadConfig()
+
+	// Connect without repository.InitDB: its AutoMigrate fails on databases the other
+	// goal migrations have not been run on yet
+	db, err := gorm.Open(postgres.Open(cfg.DatabaseURL), &gorm.Config{})
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	solvedRepo := repository.NewSolvedProblemRepository(db)
+
+	for _, table := range []string{"weekly_goals", "weekly_goal_archives"} {
+		if !db.Migrator().HasTable(table) {
+			log.Printf("Skipping %s: table does not exist yet", table)
+			continue
+		}
+		upgraded, skipped, err := upgradeTable(db, solvedRepo, table)
+		if err != nil {
+			log.Fatalf("Failed to upgrade %s: %v", table, err)
+		}
+		log.Printf("Upgraded %d rows of %s, skipped %d unreadable rows", upgraded, table, skipped)
+	}
+
+	log.Println("Successfully upgraded selected problems")
+}
+
+// upgradeTable rewrites the table in batches, each saved in one transaction
+func upgradeTable(db *gorm.DB, solvedRepo repository.SolvedProblemRepository, table string) (upgraded, skipped int, err error) {
+	ctx := context.Background()
+	slugsByUser := make(map[uuid.UUID]map[string]string)
+	lastID := uuid.Nil
+	for {
+		var rows []goalRow
+		err := db.Table(table).
+			Select("id, user_id, week_start_date, selected_problems").
+			Where("id > ? AND jsonb_typeof(selected_problems) = 'object' AND selected_problems->'version' IS NULL", lastID).
+			Order("id").
+			Limit(batchSize).
+			Scan(&rows).Error
+		if err != nil {
+			return upgraded, skipped, err
+		}
+		if len(rows) == 0 {
+			return upgraded, skipped, nil
+		}
+		lastID = rows[len(rows)-1].ID
+
+		updates := make(map[uuid.UUID]datatypes.JSON, len(rows))
+		for _, row := range rows {
+			set, err := models.ParseGoalProblemSet(row.SelectedProblems)
+			if err != nil {
+				log.Printf("Skipping %s %s: %v", table, row.ID, err)
+				skipped++
+				continue
+			}
+
+			for i := range set.Problems {
+				problem := &set.Problems[i]
+				if problem.TitleSlug != "" {
+					continue
+				}
+				if _, ok := slugsByUser[row.UserID]; !ok {
+					if slugsByUser[row.UserID], err = services.SolvedSlugsByTitle(ctx, solvedRepo, row.UserID); err != nil {
+						return upgraded, skipped, err
+					}
+				}
+				problem.TitleSlug = slugsByUser[row.UserID][strings.ToLower(problem.Title)]
+			}
+
+			encoded, err := json.Marshal(models.NewGoalProblemSet(set.Problems, row.WeekStartDate))
+			if err != nil {
+				return upgraded, skipped, err
+			}
+			updates[row.ID] = datatypes.JSON(encoded)
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			for id, selected := range updates {
+				if err := tx.Table(table).Where("id = ?", id).Update("selected_problems", selected).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return upgraded, skipped, err
+		}
+		upgraded += len(updates)
+	}
+}
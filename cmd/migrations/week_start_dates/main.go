@@ -0,0 +1,63 @@
+package main
+
+import (
+	"log"
+
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/config"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// Converts week_start_date of weekly goals and their archive from a timestamp to a date,
+// and adds the week_timezone column. Existing weeks were computed in UTC, so they are
+// converted in UTC whatever the session timezone and keep 'UTC' as their zone.
+// Run it before starting a server with timezone-aware weeks. It is safe to run twice.
+// Usage: go run cmd/migrations/week_start_dates/main.go
+func main() {
+	cfg := config.LoadConfig()
+
+	// Connect without repository.InitDB so AutoMigrate does not convert the column first
+	db, err := gorm.Open(postgres.Open(cfg.DatabaseURL), &gorm.Config{})
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for _, table := range []string{"weekly_goals", "weekly_goal_archives"} {
+			var dataType string
+			err := tx.Raw(`
+				SELECT data_type FROM information_schema.columns
+				WHERE table_schema = current_schema() AND table_name = ? AND column_name = 'week_start_date'
+			`, table).Scan(&dataType).Error
+			if err != nil {
+				return err
+			}
+			if dataType == "" {
+				log.Printf("Skipping %s: table does not exist yet", table)
+				continue
+			}
+
+			if dataType != "date" {
+				// A timestamp without time zone already holds the UTC wall time
+				using := "(week_start_date AT TIME ZONE 'UTC')::date"
+				if dataType == "timestamp without time zone" {
+					using = "week_start_date::date"
+				}
+				if err := tx.Exec(`ALTER TABLE ` + table + ` ALTER COLUMN week_start_date TYPE date USING ` + using).Error; err != nil {
+					return err
+				}
+				log.Printf("Converted %s.week_start_date from %s to date", table, dataType)
+			}
+
+			if err := tx.Exec(`ALTER TABLE ` + table + ` ADD COLUMN IF NOT EXISTS week_timezone varchar(64) NOT NULL DEFAULT 'UTC'`).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Fatalf("Failed to migrate week start dates: %v", err)
+	}
+
+	log.Println("Successfully migrated week start dates")
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"flag"
+	"log"
+
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/config"
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/models"
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/repository"
+)
+
+// Bootstraps the first admin, after which admins can manage roles through the API.
+// Usage: go run cmd/promote_admin/main.go -username <leetcode username>
+func main() {
+	username := flag.String("username", "", "username of the user to promote")
+	flag.Parse()
+	if *username == "" {
+		log.Fatal("-username is required")
+	}
+
+	cfg := config.LoadConfig()
+	repository.InitDB(cfg)
+
+	res := repository.DB.Model(&models.User{}).Where("username = ?", *username).Update("role", models.RoleAdmin)
+	if res.Error != nil {
+		log.Fatalf("Failed to promote user: %v", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		log.Fatalf("No user named %q", *username)
+	}
+
+	log.Printf("Promoted %s to admin", *username)
+}
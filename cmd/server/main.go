@@ -3,11 +3,16 @@ package main
 import (
 	"context"
 	"log"
+	"time"
+	_ "time/tzdata" // Users' timezones must load on hosts without a zoneinfo database
 
 	lcResult "github.com/devlpr-nitish/leetcode-tracker-backend/internal/clients/leetcode"
 	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/config"
 	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/handlers"
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/jobs"
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/mailer"
 	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/repository"
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/scheduler"
 	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/services"
 	"github.com/devlpr-nitish/leetcode-tracker-backend/pkg/leetcode"
 	"github.com/labstack/echo/v4"
@@ -27,16 +32,40 @@ func main() {
 	// New Client for User Profile
 	userLeetCodeClient := lcResult.NewClient()
 
+	mail, err := mailer.New(cfg)
+	if err != nil {
+		log.Fatalf("Failed to configure mailer: %v", err)
+	}
+
 	userRepo := repository.NewUserRepository(repository.DB)
 	goalRepo := repository.NewGoalRepository(repository.DB)
-	problemRepo := repository.NewProblemRepository(leetcodeClient)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(repository.DB)
+	userTokenRepo := repository.NewUserTokenRepository(repository.DB)
+	recoveryCodeRepo := repository.NewRecoveryCodeRepository(repository.DB)
+	failedLoginRepo := repository.NewFailedLoginRepository(repository.DB)
+	apiKeyRepo := repository.NewAPIKeyRepository(repository.DB)
+	statSnapshotRepo := repository.NewStatSnapshotRepository(repository.DB)
+	syncEventRepo := repository.NewSyncEventRepository(repository.DB)
+	dailyActivityRepo := repository.NewDailyActivityRepository(repository.DB)
+	solvedProblemRepo := repository.NewSolvedProblemRepository(repository.DB)
+	activityRepo := repository.NewActivityRepository(repository.DB)
+	goalPreferencesRepo := repository.NewGoalPreferencesRepository(repository.DB)
+
+	var loginAttemptStore repository.LoginAttemptStore
+	if cfg.LoginAttemptStore == "memory" {
+		loginAttemptStore = repository.NewMemoryLoginAttemptStore()
+	} else {
+		loginAttemptStore = repository.NewPostgresLoginAttemptStore(repository.DB)
+	}
+	problemRepo := repository.NewProblemRepository(leetcodeClient, cfg.TopicCatalogTTL)
 
-	userService := services.NewUserService(userRepo, userLeetCodeClient)
-	goalService := services.NewGoalService(userRepo, goalRepo, problemRepo)
-	authService := services.NewAuthService(userRepo, cfg)
+	userService := services.NewUserService(userRepo, statSnapshotRepo, syncEventRepo, dailyActivityRepo, solvedProblemRepo, activityRepo, userLeetCodeClient)
+	topicService := services.NewTopicService(userRepo, problemRepo)
+	goalService := services.NewGoalService(userRepo, goalRepo, problemRepo, solvedProblemRepo, activityRepo, topicService, goalPreferencesRepo, cfg)
+	userService.AddSyncListener(goalService.HandleSync)
+	loginGuard := services.NewLoginGuard(loginAttemptStore, failedLoginRepo, cfg)
+	authService := services.NewAuthService(userRepo, refreshTokenRepo, userTokenRepo, recoveryCodeRepo, apiKeyRepo, loginGuard, mail, cfg)
 
-	userHandler := handlers.NewUserHandler(userService)
-	goalHandler := handlers.NewGoalHandler(goalService, userService)
 	authHandler := handlers.NewAuthHandler(authService)
 
 	// GenAI Client
@@ -46,7 +75,52 @@ func main() {
 	}
 
 	comparisonService := services.NewComparisonService(genaiClient)
-	comparisonHandler := handlers.NewComparisonHandler(comparisonService)
+
+	// Job Queue (sync, goal generation and comparisons run outside the request)
+	jobRepo := repository.NewJobRepository(repository.DB)
+	jobService := services.NewJobService(jobRepo, userRepo, userService, goalService, comparisonService, cfg)
+	jobHandler := handlers.NewJobHandler(jobService)
+
+	userHandler := handlers.NewUserHandler(userService, topicService, jobService)
+	goalHandler := handlers.NewGoalHandler(goalService, userService, jobService)
+	comparisonHandler := handlers.NewComparisonHandler(comparisonService, jobService)
+
+	adminService := services.NewAdminService(userRepo, refreshTokenRepo, apiKeyRepo, repository.NewComparisonRepository(), userService, goalService)
+	adminHandler := handlers.NewAdminHandler(adminService)
+
+	accountService := services.NewAccountService(userRepo, refreshTokenRepo, apiKeyRepo, authService, cfg)
+	accountHandler := handlers.NewAccountHandler(accountService)
+
+	exportService := services.NewExportService(userRepo, goalRepo, activityRepo, repository.NewComparisonRepository(), statSnapshotRepo, syncEventRepo, dailyActivityRepo, solvedProblemRepo, goalPreferencesRepo, repository.NewDataExportRepository(repository.DB), cfg)
+	accountService.AddPurgeHook(exportService.DeleteUserExports)
+	exportHandler := handlers.NewExportHandler(exportService)
+
+	// Background Tasks
+	ctx := context.Background()
+
+	worker := jobs.NewWorker(jobRepo, cfg)
+	jobService.RegisterHandlers(worker)
+	go worker.Run(ctx)
+
+	// Requeue jobs abandoned by crashed workers and drop old finished ones
+	go scheduler.Every(ctx, time.Minute, "Job maintenance", jobService.Maintain)
+
+	// Mark goals of ended weeks as COMPLETED or FAILED
+	go scheduler.Every(ctx, time.Hour, "Goal finalization", goalService.FinalizeEndedGoals)
+
+	// Archive goals that fell outside the retention window
+	go scheduler.Every(ctx, time.Hour, "Goal cleanup", goalService.CleanupAllGoals)
+
+	// Purge accounts whose deletion grace period has ended
+	go scheduler.Every(ctx, cfg.AccountPurgeInterval, "Account purge", accountService.PurgeDeletedAccounts)
+
+	// Remove export archives whose download links have expired
+	go scheduler.Every(ctx, time.Hour, "Export cleanup", exportService.CleanupExpired)
+
+	// Keep verified users' LeetCode data fresh without them having to hit sync
+	if cfg.SyncSchedulerEnabled {
+		go scheduler.NewSyncScheduler(userRepo, userService, cfg).Run(ctx)
+	}
 
 	e := echo.New()
 	e.Use(middleware.Logger())
@@ -60,7 +134,7 @@ func main() {
 		AllowMethods: []string{echo.GET, echo.POST, echo.PUT, echo.DELETE, echo.PATCH},
 		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization},
 	}))
-	handlers.RegisterRoutes(e, userHandler, goalHandler, authHandler, comparisonHandler)
+	handlers.RegisterRoutes(e, userHandler, goalHandler, authHandler, comparisonHandler, adminHandler, accountHandler, exportHandler, jobHandler)
 
 	log.Printf("Starting server on port %s", cfg.Port)
 	if err := e.Start(":" + cfg.Port); err != nil {
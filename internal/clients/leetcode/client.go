@@ -73,6 +73,16 @@ func (c *Client) GetUserCalendar(username string) (*CalendarResponse, error) {
 	return &resp, nil
 }
 
+// GetRecentAcSubmissions returns up to limit of the user's most recent accepted submissions
+func (c *Client) GetRecentAcSubmissions(username string, limit int) (*AcSubmissionResponse, error) {
+	url := fmt.Sprintf("%s/%s/acSubmission?limit=%d", c.BaseURL, username, limit)
+	var resp AcSubmissionResponse
+	if err := c.fetch(url, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
 func (c *Client) fetch(url string, target interface{}) error {
 	resp, err := c.HTTPClient.Get(url)
 	if err != nil {
@@ -97,9 +107,14 @@ type AllUserData struct {
 	Skills   *SkillsResponse
 	Contest  *ContestResponse
 	Calendar *CalendarResponse
-	Errors   []error
+	// Recent accepted submissions. LeetCode only exposes the latest ones.
+	AcSubmissions *AcSubmissionResponse
+	Errors        []error
 }
 
+// recentAcSubmissionLimit is the most recent accepted submissions LeetCode returns
+const recentAcSubmissionLimit = 20
+
 func (c *Client) FetchAllUserData(username string) *AllUserData {
 	var wg sync.WaitGroup
 	result := &AllUserData{}
@@ -156,6 +171,16 @@ func (c *Client) FetchAllUserData(username string) *AllUserData {
 				result.Calendar = res
 			}
 		},
+		func() {
+			res, err := c.GetRecentAcSubmissions(username, recentAcSubmissionLimit)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				result.Errors = append(result.Errors, fmt.Errorf("acSubmission: %w", err))
+			} else {
+				result.AcSubmissions = res
+			}
+		},
 	}
 
 	wg.Add(len(fetchops))
@@ -72,3 +72,18 @@ type CalendarResponse struct {
 	ActiveYears        []int  `json:"activeYears"`
 	SubmissionCalendar string `json:"submissionCalendar"` // JSON string
 }
+
+// AcSubmissionResponse represents the response from GET /<username>/acSubmission
+type AcSubmissionResponse struct {
+	Count      int            `json:"count"`
+	Submission []AcSubmission `json:"submission"`
+}
+
+// AcSubmission is one recent accepted submission
+type AcSubmission struct {
+	Title         string `json:"title"`
+	TitleSlug     string `json:"titleSlug"`
+	Timestamp     string `json:"timestamp"` // Unix seconds, as a string
+	StatusDisplay string `json:"statusDisplay"`
+	Lang          string `json:"lang"`
+}
@@ -3,6 +3,8 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -12,6 +14,66 @@ type Config struct {
 	DatabaseURL string
 	LeetCodeAPI string
 	JWTSecret   string
+
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	TOTPIssuer      string
+
+	// Brute-force protection
+	LoginAttemptStore  string // postgres or memory
+	LoginMaxFailures   int    // Failures per account/identifier before lockout
+	LoginMaxIPFailures int    // Failures per IP before lockout
+	LoginLockoutBase   time.Duration
+	LoginLockoutMax    time.Duration
+	LoginFailureWindow time.Duration // Counters restart after this long without failures
+
+	AccountDeletionGrace time.Duration // How long a deleted account can be restored before its data is purged
+	AccountPurgeInterval time.Duration
+
+	// Background sync of verified users
+	SyncSchedulerEnabled bool
+	SyncInterval         time.Duration // Target time between two scheduled syncs of the same user
+	SyncJitter           time.Duration // Random extra delay so users do not all come due at once
+	SyncConcurrency      int           // Max syncs running against the LeetCode API at the same time
+	SyncMinAge           time.Duration // Users synced more recently than this (e.g. manually) are skipped
+	SyncPollInterval     time.Duration // How often the scheduler looks for due users
+
+	// Weekly goal generation
+	GoalExcludeRecentWeeks int           // Problems assigned in this many previous weeks are not assigned again
+	GoalFinalizeGrace      time.Duration // Wait after a goal week ends before deciding it, so late syncs still count
+	GoalRetentionPastWeeks int           // Goals of weeks before the current one kept live
+	GoalRetentionNextWeeks int           // Goals of weeks after the current one kept live
+	GoalArchiveEnabled     bool          // Copy goals to weekly_goal_archives before cleanup deletes them
+
+	// Topic mastery
+	TopicCatalogTTL time.Duration // How long per-topic problem counts from the problem API are cached
+
+	// Background job queue
+	JobWorkers      int           // Jobs processed concurrently by this server
+	JobPollInterval time.Duration // Wait between polls when the queue is empty
+	JobTimeout      time.Duration // Max duration of one attempt
+	JobMaxAttempts  int
+	JobRetryBase    time.Duration // Delay before the first retry, doubled on each further failure
+	JobRetryMax     time.Duration
+	JobStaleAfter   time.Duration // Running jobs locked longer than this are assumed abandoned and requeued
+	JobRetention    time.Duration // Finished jobs are deleted after this long
+
+	// Personal data exports
+	ExportDir         string
+	ExportLinkTTL     time.Duration // How long a finished archive can be downloaded
+	ExportSyncMaxRows int64         // Accounts with more rows than this are exported in the background
+	ExportStaleAfter  time.Duration // Pending or running exports untouched for this long are assumed abandoned and failed
+
+	// Public URL of the frontend, used to build links in emails
+	AppBaseURL string
+
+	MailDriver   string // smtp, file or memory
+	MailFrom     string
+	MailDir      string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
 }
 
 func LoadConfig() *Config {
@@ -24,6 +86,59 @@ func LoadConfig() *Config {
 		DatabaseURL: getEnv("DB_URL", "host=localhost user=postgres password=postgres dbname=leetcode_tracker port=5432 sslmode=disable"),
 		LeetCodeAPI: getEnv("LEETCODE_API_URL", "https://leetcode.com/graphql"),
 		JWTSecret:   getEnv("JWT_SECRET", "super-secret-key-change-me"),
+
+		AccessTokenTTL:  getDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+		RefreshTokenTTL: getDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+		TOTPIssuer:      getEnv("TOTP_ISSUER", "LeetCode Tracker"),
+
+		LoginAttemptStore:  getEnv("LOGIN_ATTEMPT_STORE", "postgres"),
+		LoginMaxFailures:   getInt("LOGIN_MAX_FAILURES", 5),
+		LoginMaxIPFailures: getInt("LOGIN_MAX_IP_FAILURES", 20),
+		LoginLockoutBase:   getDuration("LOGIN_LOCKOUT_BASE", time.Minute),
+		LoginLockoutMax:    getDuration("LOGIN_LOCKOUT_MAX", time.Hour),
+		LoginFailureWindow: getDuration("LOGIN_FAILURE_WINDOW", 24*time.Hour),
+
+		AccountDeletionGrace: getDuration("ACCOUNT_DELETION_GRACE", 30*24*time.Hour),
+		AccountPurgeInterval: getDuration("ACCOUNT_PURGE_INTERVAL", time.Hour),
+
+		SyncSchedulerEnabled: getBool("SYNC_SCHEDULER_ENABLED", true),
+		SyncInterval:         getDuration("SYNC_INTERVAL", 6*time.Hour),
+		SyncJitter:           getDuration("SYNC_JITTER", 30*time.Minute),
+		SyncConcurrency:      getInt("SYNC_CONCURRENCY", 4),
+		SyncMinAge:           getDuration("SYNC_MIN_AGE", time.Hour),
+		SyncPollInterval:     getDuration("SYNC_POLL_INTERVAL", time.Minute),
+
+		GoalExcludeRecentWeeks: getInt("GOAL_EXCLUDE_RECENT_WEEKS", 4),
+		GoalFinalizeGrace:      getDuration("GOAL_FINALIZE_GRACE", 12*time.Hour),
+		GoalRetentionPastWeeks: getInt("GOAL_RETENTION_PAST_WEEKS", 1),
+		GoalRetentionNextWeeks: getInt("GOAL_RETENTION_NEXT_WEEKS", 1),
+		GoalArchiveEnabled:     getBool("GOAL_ARCHIVE_ENABLED", true),
+
+		TopicCatalogTTL: getDuration("TOPIC_CATALOG_TTL", 24*time.Hour),
+
+		JobWorkers:      getInt("JOB_WORKERS", 4),
+		JobPollInterval: getDuration("JOB_POLL_INTERVAL", 2*time.Second),
+		JobTimeout:      getDuration("JOB_TIMEOUT", 5*time.Minute),
+		JobMaxAttempts:  getInt("JOB_MAX_ATTEMPTS", 5),
+		JobRetryBase:    getDuration("JOB_RETRY_BASE", 30*time.Second),
+		JobRetryMax:     getDuration("JOB_RETRY_MAX", 30*time.Minute),
+		JobStaleAfter:   getDuration("JOB_STALE_AFTER", 15*time.Minute),
+		JobRetention:    getDuration("JOB_RETENTION", 7*24*time.Hour),
+
+		ExportDir:         getEnv("EXPORT_DIR", "tmp/exports"),
+		ExportLinkTTL:     getDuration("EXPORT_LINK_TTL", 24*time.Hour),
+		ExportSyncMaxRows: int64(getInt("EXPORT_SYNC_MAX_ROWS", 2000)),
+		ExportStaleAfter:  getDuration("EXPORT_STALE_AFTER", 30*time.Minute),
+
+		AppBaseURL: getEnv("APP_BASE_URL", "http://localhost:5173"),
+
+		MailDriver:   getEnv("MAIL_DRIVER", "file"),
+		MailFrom:     getEnv("MAIL_FROM", "LeetCode Tracker <no-reply@localhost>"),
+		MailDir:      getEnv("MAIL_DIR", "tmp/mail"),
+		SMTPHost:     getEnv("SMTP_HOST", "localhost"),
+		SMTPPort:     getEnv("SMTP_PORT", "587"),
+		SMTPUsername: getEnv("SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
 	}
 }
 
@@ -33,3 +148,42 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+func getInt(key string, fallback int) int {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Invalid integer for %s (%q), using default %d", key, value, fallback)
+		return fallback
+	}
+	return n
+}
+
+func getBool(key string, fallback bool) bool {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Invalid boolean for %s (%q), using default %t", key, value, fallback)
+		return fallback
+	}
+	return b
+}
+
+func getDuration(key string, fallback time.Duration) time.Duration {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Invalid duration for %s (%q), using default %s", key, value, fallback)
+		return fallback
+	}
+	return d
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/services"
+	"github.com/labstack/echo/v4"
+)
+
+type AccountHandler struct {
+	AccountService *services.AccountService
+}
+
+func NewAccountHandler(accountService *services.AccountService) *AccountHandler {
+	return &AccountHandler{AccountService: accountService}
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
+
+type RestoreAccountRequest struct {
+	Identifier string `json:"identifier"` // Username or Email
+	Password   string `json:"password"`
+}
+
+func (h *AccountHandler) UpdateProfile(c echo.Context) error {
+	var req services.ProfileUpdate
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	user, err := h.AccountService.UpdateProfile(c.Request().Context(), CurrentUser(c), req)
+	if err != nil {
+		return accountError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, user)
+}
+
+func (h *AccountHandler) ChangePassword(c echo.Context) error {
+	var req ChangePasswordRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	if err := h.AccountService.ChangePassword(c.Request().Context(), CurrentPrincipal(c), req.CurrentPassword, req.NewPassword); err != nil {
+		return accountError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Password updated, other sessions were signed out"})
+}
+
+func (h *AccountHandler) DeleteAccount(c echo.Context) error {
+	var req DeleteAccountRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	user, err := h.AccountService.DeleteAccount(c.Request().Context(), CurrentUser(c), req.Password)
+	if err != nil {
+		return accountError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message":               "Account deleted. You can restore it until the scheduled purge date.",
+		"deletion_scheduled_at": user.DeletionScheduledAt,
+	})
+}
+
+func (h *AccountHandler) RestoreAccount(c echo.Context) error {
+	var req RestoreAccountRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	user, err := h.AccountService.RestoreAccount(c.Request().Context(), req.Identifier, req.Password, clientInfo(c))
+	if err != nil {
+		var locked *services.LockedError
+		if errors.As(err, &locked) {
+			return loginError(c, err)
+		}
+		return accountError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, user)
+}
+
+func accountError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidCredentials):
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	case errors.Is(err, services.ErrEmailTaken):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidEmail), errors.Is(err, services.ErrInvalidDisplayName), errors.Is(err, services.ErrWeakPassword),
+		errors.Is(err, services.ErrUnknownStrategy), errors.Is(err, services.ErrInvalidTimezone), errors.Is(err, services.ErrInvalidWeekStart):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, services.ErrRestoreExpired):
+		return c.JSON(http.StatusGone, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+}
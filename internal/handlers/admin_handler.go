@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/services"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type AdminHandler struct {
+	AdminService *services.AdminService
+}
+
+func NewAdminHandler(adminService *services.AdminService) *AdminHandler {
+	return &AdminHandler{AdminService: adminService}
+}
+
+type SetRoleRequest struct {
+	Role string `json:"role"`
+}
+
+// ListUsers supports ?q=, ?role=, ?include_deleted=true, ?page= and ?limit=
+func (h *AdminHandler) ListUsers(c echo.Context) error {
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	includeDeleted, _ := strconv.ParseBool(c.QueryParam("include_deleted"))
+
+	result, err := h.AdminService.ListUsers(c.Request().Context(), c.QueryParam("q"), c.QueryParam("role"), includeDeleted, page, limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to list users"})
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func (h *AdminHandler) SyncUser(c echo.Context) error {
+	user, err := h.AdminService.ForceSync(c.Request().Context(), c.Param("username"))
+	if err != nil {
+		return adminError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, user)
+}
+
+func (h *AdminHandler) RegenerateGoals(c echo.Context) error {
+	opts, err := parseGenerationOptions(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	result, err := h.AdminService.RegenerateGoals(c.Request().Context(), c.Param("username"), opts)
+	if err != nil {
+		return adminError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// PurgeComparisons supports ?username= and ?older_than= (Go duration, default 0 = everything)
+func (h *AdminHandler) PurgeComparisons(c echo.Context) error {
+	var olderThan time.Duration
+	if raw := c.QueryParam("older_than"); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil || d < 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "older_than must be a duration such as 24h"})
+		}
+		olderThan = d
+	}
+
+	deleted, err := h.AdminService.PurgeComparisons(c.QueryParam("username"), olderThan)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to purge comparisons"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]int64{"deleted": deleted})
+}
+
+func (h *AdminHandler) SetRole(c echo.Context) error {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid user id"})
+	}
+	var req SetRoleRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	user, err := h.AdminService.SetRole(c.Request().Context(), CurrentUser(c), userID, req.Role)
+	if err != nil {
+		return adminError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, user)
+}
+
+func (h *AdminHandler) DeleteUser(c echo.Context) error {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid user id"})
+	}
+
+	if err := h.AdminService.DeleteUser(c.Request().Context(), CurrentUser(c), userID); err != nil {
+		return adminError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *AdminHandler) RestoreUser(c echo.Context) error {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid user id"})
+	}
+
+	user, err := h.AdminService.RestoreUser(c.Request().Context(), userID)
+	if err != nil {
+		return adminError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, user)
+}
+
+func adminError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, services.ErrAdminUserNotFound), errors.Is(err, services.ErrUserNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": services.ErrAdminUserNotFound.Error()})
+	case errors.Is(err, services.ErrInvalidRole), errors.Is(err, services.ErrUserNotDeleted), errors.Is(err, services.ErrCannotDeleteSelf),
+		errors.Is(err, services.ErrInvalidGoalWeek), errors.Is(err, services.ErrGoalWeekRetained), errors.Is(err, services.ErrUnknownStrategy):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, services.ErrGoalFinalized):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+}
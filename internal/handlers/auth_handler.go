@@ -1,9 +1,14 @@
 package handlers
 
 import (
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/services"
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
 
@@ -26,6 +31,44 @@ type LoginRequest struct {
 	Password   string `json:"password"`
 }
 
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+	RecoveryCode   string `json:"recovery_code"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code"`
+}
+
+type DisableTwoFactorRequest struct {
+	Password string `json:"password"`
+	Code     string `json:"code"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expires_in_days"` // 0 = never expires
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
 func (h *AuthHandler) Signup(c echo.Context) error {
 	var req SignupRequest
 	if err := c.Bind(&req); err != nil {
@@ -46,10 +89,263 @@ func (h *AuthHandler) Login(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
 	}
 
-	token, err := h.AuthService.Login(c.Request().Context(), req.Identifier, req.Password)
+	token, err := h.AuthService.Login(c.Request().Context(), req.Identifier, req.Password, clientInfo(c))
 	if err != nil {
-		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+		return loginError(c, err)
 	}
 
 	return c.JSON(http.StatusOK, token)
 }
+
+func (h *AuthHandler) Refresh(c echo.Context) error {
+	var req RefreshRequest
+	if err := c.Bind(&req); err != nil || req.RefreshToken == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "refresh_token is required"})
+	}
+
+	token, err := h.AuthService.Refresh(c.Request().Context(), req.RefreshToken, clientInfo(c))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidToken) || errors.Is(err, services.ErrRefreshTokenReuse) {
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to refresh token"})
+	}
+
+	return c.JSON(http.StatusOK, token)
+}
+
+func (h *AuthHandler) Logout(c echo.Context) error {
+	if err := h.AuthService.Logout(c.Request().Context(), CurrentPrincipal(c)); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to log out"})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *AuthHandler) ListSessions(c echo.Context) error {
+	sessions, err := h.AuthService.ListSessions(c.Request().Context(), CurrentPrincipal(c))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to list sessions"})
+	}
+
+	return c.JSON(http.StatusOK, sessions)
+}
+
+func (h *AuthHandler) RevokeSession(c echo.Context) error {
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid session id"})
+	}
+
+	if err := h.AuthService.RevokeSession(c.Request().Context(), CurrentPrincipal(c), sessionID); err != nil {
+		if errors.Is(err, services.ErrSessionNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to revoke session"})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *AuthHandler) VerifyEmail(c echo.Context) error {
+	var req VerifyEmailRequest
+	if err := c.Bind(&req); err != nil || req.Token == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "token is required"})
+	}
+
+	user, err := h.AuthService.VerifyEmail(c.Request().Context(), req.Token)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidEmailToken) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to verify email"})
+	}
+
+	return c.JSON(http.StatusOK, user)
+}
+
+// ResendEmailVerification sends a fresh verification link to the authenticated user
+func (h *AuthHandler) ResendEmailVerification(c echo.Context) error {
+	user := CurrentUser(c)
+	if user.EmailVerified {
+		return c.JSON(http.StatusConflict, map[string]string{"error": "Email already verified"})
+	}
+
+	if err := h.AuthService.SendEmailVerification(c.Request().Context(), user); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to send verification email"})
+	}
+
+	return c.JSON(http.StatusAccepted, map[string]string{"message": "Verification email sent"})
+}
+
+func (h *AuthHandler) ForgotPassword(c echo.Context) error {
+	var req ForgotPasswordRequest
+	if err := c.Bind(&req); err != nil || req.Email == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "email is required"})
+	}
+
+	if err := h.AuthService.ForgotPassword(c.Request().Context(), req.Email); err != nil {
+		c.Logger().Errorf("forgot password: %v", err)
+	}
+
+	// Same response whether or not the address exists
+	return c.JSON(http.StatusAccepted, map[string]string{"message": "If the address is registered, a reset link has been sent"})
+}
+
+func (h *AuthHandler) ResetPassword(c echo.Context) error {
+	var req ResetPasswordRequest
+	if err := c.Bind(&req); err != nil || req.Token == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "token and password are required"})
+	}
+
+	if err := h.AuthService.ResetPassword(c.Request().Context(), req.Token, req.Password); err != nil {
+		if errors.Is(err, services.ErrInvalidEmailToken) || errors.Is(err, services.ErrWeakPassword) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to reset password"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Password updated, please log in again"})
+}
+
+// LoginTwoFactor is the second login step for accounts with 2FA enabled
+func (h *AuthHandler) LoginTwoFactor(c echo.Context) error {
+	var req TwoFactorLoginRequest
+	if err := c.Bind(&req); err != nil || req.ChallengeToken == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "challenge_token and code or recovery_code are required"})
+	}
+
+	token, err := h.AuthService.CompleteTwoFactorLogin(c.Request().Context(), req.ChallengeToken, req.Code, req.RecoveryCode, clientInfo(c))
+	if err != nil {
+		return loginError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, token)
+}
+
+func (h *AuthHandler) EnrollTwoFactor(c echo.Context) error {
+	enrollment, err := h.AuthService.EnrollTwoFactor(c.Request().Context(), CurrentUser(c))
+	if err != nil {
+		return twoFactorError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, enrollment)
+}
+
+func (h *AuthHandler) ConfirmTwoFactor(c echo.Context) error {
+	var req TwoFactorCodeRequest
+	if err := c.Bind(&req); err != nil || req.Code == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "code is required"})
+	}
+
+	codes, err := h.AuthService.ConfirmTwoFactor(c.Request().Context(), CurrentUser(c), req.Code)
+	if err != nil {
+		return twoFactorError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, codes)
+}
+
+func (h *AuthHandler) RegenerateRecoveryCodes(c echo.Context) error {
+	var req TwoFactorCodeRequest
+	if err := c.Bind(&req); err != nil || req.Code == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "code is required"})
+	}
+
+	codes, err := h.AuthService.RegenerateRecoveryCodes(c.Request().Context(), CurrentUser(c), req.Code)
+	if err != nil {
+		return twoFactorError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, codes)
+}
+
+func (h *AuthHandler) DisableTwoFactor(c echo.Context) error {
+	var req DisableTwoFactorRequest
+	if err := c.Bind(&req); err != nil || req.Password == "" || req.Code == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "password and code are required"})
+	}
+
+	if err := h.AuthService.DisableTwoFactor(c.Request().Context(), CurrentUser(c), req.Password, req.Code); err != nil {
+		return twoFactorError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func twoFactorError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, services.ErrTwoFactorAlreadyEnabled):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, services.ErrTwoFactorNotEnrolled), errors.Is(err, services.ErrTwoFactorNotEnabled):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidTwoFactorCode), errors.Is(err, services.ErrInvalidCredentials):
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Two-factor operation failed"})
+}
+
+// FailedLogins lists recent failed login attempts against the authenticated user's account
+func (h *AuthHandler) FailedLogins(c echo.Context) error {
+	entries, err := h.AuthService.Guard.FailedLogins(c.Request().Context(), CurrentUser(c).ID, 50)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to list failed logins"})
+	}
+
+	return c.JSON(http.StatusOK, entries)
+}
+
+func loginError(c echo.Context, err error) error {
+	var locked *services.LockedError
+	switch {
+	case errors.As(err, &locked):
+		c.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(locked.RetryAfter.Seconds()))))
+		return c.JSON(http.StatusTooManyRequests, map[string]string{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidCredentials), errors.Is(err, services.ErrInvalidToken), errors.Is(err, services.ErrInvalidTwoFactorCode):
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to log in"})
+}
+
+func (h *AuthHandler) CreateAPIKey(c echo.Context) error {
+	var req CreateAPIKeyRequest
+	if err := c.Bind(&req); err != nil || req.ExpiresInDays < 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	expiresIn := time.Duration(req.ExpiresInDays) * 24 * time.Hour
+	key, err := h.AuthService.CreateAPIKey(c.Request().Context(), CurrentUser(c), req.Name, req.Scopes, expiresIn)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidAPIKeyReq) || errors.Is(err, services.ErrTooManyAPIKeys) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create API key"})
+	}
+
+	return c.JSON(http.StatusCreated, key)
+}
+
+func (h *AuthHandler) ListAPIKeys(c echo.Context) error {
+	keys, err := h.AuthService.ListAPIKeys(c.Request().Context(), CurrentUser(c))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to list API keys"})
+	}
+
+	return c.JSON(http.StatusOK, keys)
+}
+
+func (h *AuthHandler) RevokeAPIKey(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid API key id"})
+	}
+
+	if err := h.AuthService.RevokeAPIKey(c.Request().Context(), CurrentUser(c), id); err != nil {
+		if errors.Is(err, services.ErrAPIKeyNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to revoke API key"})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -8,11 +8,12 @@ import (
 )
 
 type ComparisonHandler struct {
-	service *services.ComparisonService
+	service    *services.ComparisonService
+	jobService *services.JobService
 }
 
-func NewComparisonHandler(service *services.ComparisonService) *ComparisonHandler {
-	return &ComparisonHandler{service: service}
+func NewComparisonHandler(service *services.ComparisonService, jobService *services.JobService) *ComparisonHandler {
+	return &ComparisonHandler{service: service, jobService: jobService}
 }
 
 type CompareRequest struct {
@@ -40,10 +41,16 @@ func (h *ComparisonHandler) CompareUsers(c echo.Context) error {
 		req.User2Name = "User 2"
 	}
 
-	result, err := h.service.CompareUsers(c.Request().Context(), req.User1Name, req.User1Data, req.User2Name, req.User2Data)
+	// Gemini can take a while, so the comparison runs in the job queue
+	job, err := h.jobService.EnqueueComparison(c.Request().Context(), CurrentUser(c), services.ComparisonJobPayload{
+		User1Name: req.User1Name,
+		User1Data: req.User1Data,
+		User2Name: req.User2Name,
+		User2Data: req.User2Data,
+	})
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to queue comparison"})
 	}
 
-	return c.JSON(http.StatusOK, result)
+	return jobAccepted(c, job)
 }
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/services"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type ExportHandler struct {
+	ExportService *services.ExportService
+}
+
+func NewExportHandler(exportService *services.ExportService) *ExportHandler {
+	return &ExportHandler{ExportService: exportService}
+}
+
+// Export streams the caller's archive directly, or queues it and returns 202 for large accounts
+func (h *ExportHandler) Export(c echo.Context) error {
+	ctx := c.Request().Context()
+	user := CurrentUser(c)
+
+	large, err := h.ExportService.IsLarge(ctx, user)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to prepare export"})
+	}
+
+	if large || c.QueryParam("async") == "true" {
+		status, err := h.ExportService.StartExport(ctx, user)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to start export"})
+		}
+		return c.JSON(http.StatusAccepted, h.statusResponse(c, status))
+	}
+
+	filename := fmt.Sprintf("leetcode-tracker-%s-%s.zip", user.Username, time.Now().UTC().Format("20060102"))
+	c.Response().Header().Set(echo.HeaderContentType, "application/zip")
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	c.Response().WriteHeader(http.StatusOK)
+
+	// Headers are already sent, so a failure here can only be logged
+	if err := h.ExportService.WriteArchive(ctx, user, c.Response()); err != nil {
+		c.Logger().Errorf("export for %s failed mid-stream: %v", user.Username, err)
+	}
+	return nil
+}
+
+func (h *ExportHandler) GetExport(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid export id"})
+	}
+
+	status, err := h.ExportService.GetStatus(c.Request().Context(), CurrentUser(c), id)
+	if err != nil {
+		return exportError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, h.statusResponse(c, status))
+}
+
+// Download serves a finished archive. The signed link is the credential, so no auth header is needed.
+func (h *ExportHandler) Download(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid export id"})
+	}
+	expires, err := strconv.ParseInt(c.QueryParam("expires"), 10, 64)
+	if err != nil {
+		return exportError(c, services.ErrInvalidExportLink)
+	}
+
+	f, export, err := h.ExportService.OpenDownload(c.Request().Context(), id, expires, c.QueryParam("signature"))
+	if err != nil {
+		return exportError(c, err)
+	}
+	defer f.Close()
+
+	filename := fmt.Sprintf("leetcode-tracker-export-%s.zip", export.CreatedAt.UTC().Format("20060102"))
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	return c.Stream(http.StatusOK, "application/zip", f)
+}
+
+// statusResponse turns the service's relative download path into an absolute URL
+func (h *ExportHandler) statusResponse(c echo.Context, status *services.ExportStatus) map[string]interface{} {
+	resp := map[string]interface{}{"export": status.DataExport}
+	if status.DownloadPath != "" {
+		resp["download_url"] = fmt.Sprintf("%s://%s%s", c.Scheme(), c.Request().Host, status.DownloadPath)
+	}
+	return resp
+}
+
+func exportError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, services.ErrExportNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidExportLink):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	case errors.Is(err, services.ErrExportNotAvailable):
+		return c.JSON(http.StatusGone, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Export failed"})
+	}
+}
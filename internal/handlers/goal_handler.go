@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/services"
 	"github.com/labstack/echo/v4"
@@ -10,56 +12,111 @@ import (
 type GoalHandler struct {
 	GoalService *services.GoalService
 	UserService *services.UserService
+	JobService  *services.JobService
 }
 
-func NewGoalHandler(goalService *services.GoalService, userService *services.UserService) *GoalHandler {
+func NewGoalHandler(goalService *services.GoalService, userService *services.UserService, jobService *services.JobService) *GoalHandler {
 	return &GoalHandler{
 		GoalService: goalService,
 		UserService: userService,
+		JobService:  jobService,
 	}
 }
 
+// GetCurrentGoals returns this week's goals, or next week's with ?week=next
 func (h *GoalHandler) GetCurrentGoals(c echo.Context) error {
-	// For simplicity, we'll assume the username is passed as a query param or auth token
-	// In a real app, this would come from JWT middleware
-	username := c.QueryParam("username")
-	if username == "" {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "username query parameter is required"})
+	user := CurrentUser(c)
+
+	goals, err := h.GoalService.GetUserGoals(c.Request().Context(), user, c.QueryParam("week"))
+	if errors.Is(err, services.ErrInvalidGoalWeek) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
-	ctx := c.Request().Context()
-	user, err := h.UserService.GetUserByUsername(ctx, username)
+	return c.JSON(http.StatusOK, goals)
+}
+
+// GenerateGoals queues goal generation for the authenticated user and returns 202 with the job ID.
+// Served for both /users/:username/goals/generate (guarded by RequireOwner) and /me/goals/generate.
+// Supports ?week=current|next, ?regenerate=true to replace the week's existing goal and
+// ?strategy= to use another planner than the user's.
+func (h *GoalHandler) GenerateGoals(c echo.Context) error {
+	opts, err := parseGenerationOptions(c)
+	if err == nil {
+		err = h.GoalService.ValidateGenerationOptions(opts)
+	}
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch user"})
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
-	if user == nil {
-		return c.JSON(http.StatusNotFound, map[string]string{"error": "User not found"})
+
+	job, err := h.JobService.EnqueueGoalGeneration(c.Request().Context(), CurrentUser(c), opts)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to queue goal generation"})
 	}
 
-	goals, err := h.GoalService.GetUserGoals(ctx, user.ID)
+	return jobAccepted(c, job)
+}
+
+// GetGoalHistory lists the authenticated user's archived goals, paged with ?page= and ?limit=
+func (h *GoalHandler) GetGoalHistory(c echo.Context) error {
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+
+	history, err := h.GoalService.ListArchivedGoals(c.Request().Context(), CurrentUser(c).ID, page, limit)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch goal history"})
 	}
 
-	return c.JSON(http.StatusOK, goals)
+	return c.JSON(http.StatusOK, history)
 }
 
-func (h *GoalHandler) GenerateGoals(c echo.Context) error {
-	// Manually trigger goal generation (for testing/admin)
-	username := c.Param("username")
-	ctx := c.Request().Context()
+func parseGenerationOptions(c echo.Context) (services.GenerationOptions, error) {
+	opts := services.GenerationOptions{Week: c.QueryParam("week"), Strategy: c.QueryParam("strategy")}
+	if raw := c.QueryParam("regenerate"); raw != "" {
+		regenerate, err := strconv.ParseBool(raw)
+		if err != nil {
+			return opts, errors.New("regenerate must be true or false")
+		}
+		opts.Regenerate = regenerate
+	}
+	return opts, nil
+}
 
-	user, err := h.UserService.GetUserByUsername(ctx, username)
+// GetPreferences returns the authenticated user's goal preferences, or the defaults if they never saved any
+func (h *GoalHandler) GetPreferences(c echo.Context) error {
+	prefs, err := h.GoalService.GetPreferences(c.Request().Context(), CurrentUser(c).ID)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch user"})
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to fetch goal preferences"})
+	}
+
+	return c.JSON(http.StatusOK, prefs)
+}
+
+// UpdatePreferences replaces the user's goal preferences; omitted fields are reset
+func (h *GoalHandler) UpdatePreferences(c echo.Context) error {
+	var req services.GoalPreferences
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
 	}
-	if user == nil {
-		return c.JSON(http.StatusNotFound, map[string]string{"error": "User not found"})
+
+	prefs, err := h.GoalService.SavePreferences(c.Request().Context(), CurrentUser(c).ID, req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidGoalPreferences) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to save goal preferences"})
 	}
 
-	if err := h.GoalService.GenerateWeeklyGoals(ctx, user.ID); err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	return c.JSON(http.StatusOK, prefs)
+}
+
+// DeletePreferences resets the user's goal preferences to the defaults
+func (h *GoalHandler) DeletePreferences(c echo.Context) error {
+	if err := h.GoalService.DeletePreferences(c.Request().Context(), CurrentUser(c).ID); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to reset goal preferences"})
 	}
 
-	return c.JSON(http.StatusOK, map[string]string{"message": "Goals generated successfully"})
+	return c.NoContent(http.StatusNoContent)
 }
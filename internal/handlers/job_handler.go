@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/models"
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/services"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type JobHandler struct {
+	JobService *services.JobService
+}
+
+func NewJobHandler(jobService *services.JobService) *JobHandler {
+	return &JobHandler{JobService: jobService}
+}
+
+// GetJob returns a job's status, and its result once it succeeded
+func (h *JobHandler) GetJob(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid job id"})
+	}
+
+	job, err := h.JobService.GetJob(c.Request().Context(), CurrentUser(c), id)
+	if err != nil {
+		if errors.Is(err, services.ErrJobNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, job)
+}
+
+// jobAccepted answers 202 with the ID of a queued job and where to poll for it
+func jobAccepted(c echo.Context, job *models.Job) error {
+	return c.JSON(http.StatusAccepted, map[string]interface{}{
+		"job_id":     job.ID,
+		"type":       job.Type,
+		"status":     job.Status,
+		"status_url": "/api/v1/jobs/" + job.ID.String(),
+	})
+}
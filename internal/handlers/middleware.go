@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/models"
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/services"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	contextUserKey      = "user"
+	contextPrincipalKey = "principal"
+)
+
+// RequireAuth authenticates the request with either a session token (`Authorization: Bearer <jwt>`)
+// or a personal API key (`Authorization: ApiKey <key>`) and stores the caller in the context
+func RequireAuth(authService *services.AuthService) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			header := c.Request().Header.Get(echo.HeaderAuthorization)
+			ctx := c.Request().Context()
+
+			var principal *services.Principal
+			var err error
+			if tokenString, ok := strings.CutPrefix(header, "Bearer "); ok && tokenString != "" {
+				principal, err = authService.Authenticate(ctx, tokenString)
+			} else if key, ok := strings.CutPrefix(header, "ApiKey "); ok && key != "" {
+				principal, err = authService.AuthenticateAPIKey(ctx, key)
+			} else {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Missing bearer token or API key"})
+			}
+
+			if err != nil {
+				if errors.Is(err, services.ErrInvalidToken) || errors.Is(err, services.ErrInvalidAPIKey) {
+					return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+				}
+				return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to authenticate"})
+			}
+
+			c.Set(contextUserKey, principal.User)
+			c.Set(contextPrincipalKey, principal)
+			return next(c)
+		}
+	}
+}
+
+// RequireScope rejects API keys that were not granted scope. Session tokens always pass.
+// It must be chained after RequireAuth.
+func RequireScope(scope string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			principal := CurrentPrincipal(c)
+			if principal == nil {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Authentication required"})
+			}
+			if !principal.HasScope(scope) {
+				return c.JSON(http.StatusForbidden, map[string]string{"error": "API key is missing the " + scope + " scope"})
+			}
+			return next(c)
+		}
+	}
+}
+
+// RequireSession restricts account management routes to callers that logged in,
+// so a leaked API key cannot be used to mint new credentials or change security settings.
+// It must be chained after RequireAuth.
+func RequireSession(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		principal := CurrentPrincipal(c)
+		if principal == nil {
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Authentication required"})
+		}
+		if principal.APIKeyID != nil {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": "This endpoint is not available to API keys"})
+		}
+		return next(c)
+	}
+}
+
+// RequireOwner only lets the request through if the path parameter names the authenticated user.
+// It must be chained after RequireAuth.
+func RequireOwner(param string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			user := CurrentUser(c)
+			if user == nil {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Authentication required"})
+			}
+			if !strings.EqualFold(c.Param(param), user.Username) {
+				return c.JSON(http.StatusForbidden, map[string]string{"error": "You can only access your own resources"})
+			}
+			return next(c)
+		}
+	}
+}
+
+// RequireVerifiedLeetCode only lets users who proved ownership of their LeetCode account through.
+// It must be chained after RequireAuth.
+func RequireVerifiedLeetCode(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		user := CurrentUser(c)
+		if user == nil {
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Authentication required"})
+		}
+		if !user.LeetCodeVerified {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": "Verify ownership of your LeetCode account first"})
+		}
+		return next(c)
+	}
+}
+
+// RequireRole only lets users with the given role through.
+// It must be chained after RequireAuth.
+func RequireRole(role string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			user := CurrentUser(c)
+			if user == nil {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Authentication required"})
+			}
+			if user.Role != role {
+				return c.JSON(http.StatusForbidden, map[string]string{"error": "Insufficient permissions"})
+			}
+			return next(c)
+		}
+	}
+}
+
+// CurrentUser returns the user loaded by RequireAuth, or nil on unauthenticated routes
+func CurrentUser(c echo.Context) *models.User {
+	user, _ := c.Get(contextUserKey).(*models.User)
+	return user
+}
+
+// CurrentPrincipal returns the principal loaded by RequireAuth, or nil on unauthenticated routes
+func CurrentPrincipal(c echo.Context) *services.Principal {
+	principal, _ := c.Get(contextPrincipalKey).(*services.Principal)
+	return principal
+}
+
+func clientInfo(c echo.Context) services.ClientInfo {
+	return services.ClientInfo{
+		UserAgent: c.Request().UserAgent(),
+		IPAddress: c.RealIP(),
+	}
+}
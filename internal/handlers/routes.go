@@ -2,27 +2,100 @@ package handlers
 
 import (
 	"net/http"
+	"time"
 
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/models"
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/services"
 	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
 )
 
-func RegisterRoutes(e *echo.Echo, userHandler *UserHandler, goalHandler *GoalHandler, authHandler *AuthHandler, comparisonHandler *ComparisonHandler) {
+func RegisterRoutes(e *echo.Echo, userHandler *UserHandler, goalHandler *GoalHandler, authHandler *AuthHandler, comparisonHandler *ComparisonHandler, adminHandler *AdminHandler, accountHandler *AccountHandler, exportHandler *ExportHandler, jobHandler *JobHandler) {
 	api := e.Group("/api/v1")
 
+	auth := RequireAuth(authHandler.AuthService)
+	owner := RequireOwner("username")
+
+	// Topic strengths call the LeetCode API for every catalog count not cached yet, so
+	// anonymous callers are throttled per IP
+	topicsLimiter := middleware.RateLimiter(middleware.NewRateLimiterMemoryStoreWithConfig(middleware.RateLimiterMemoryStoreConfig{
+		Rate:      0.2, // Requests per second
+		Burst:     5,
+		ExpiresIn: 10 * time.Minute,
+	}))
+
 	// Auth Routes
 	api.POST("/auth/signup", authHandler.Signup)
 	api.POST("/auth/login", authHandler.Login)
+	api.POST("/auth/login/2fa", authHandler.LoginTwoFactor)
+	api.POST("/auth/refresh", authHandler.Refresh)
+	api.POST("/auth/logout", authHandler.Logout, auth, RequireSession)
+	api.GET("/auth/sessions", authHandler.ListSessions, auth, RequireSession)
+	api.DELETE("/auth/sessions/:id", authHandler.RevokeSession, auth, RequireSession)
+	api.GET("/auth/failed-logins", authHandler.FailedLogins, auth, RequireSession)
+	api.POST("/auth/verify-email", authHandler.VerifyEmail)
+	api.POST("/auth/forgot-password", authHandler.ForgotPassword)
+	api.POST("/auth/reset-password", authHandler.ResetPassword)
+	api.POST("/auth/restore", accountHandler.RestoreAccount)
 
 	// User Routes
 	api.GET("/users/:username", userHandler.GetUser)
-	api.POST("/users/:username/sync", userHandler.SyncUser)
+	api.GET("/users/:username/history", userHandler.GetHistory)
+	api.GET("/users/:username/activity/heatmap", userHandler.GetHeatmap)
+	api.GET("/users/:username/activity/stats", userHandler.GetActivityStats)
+	api.GET("/users/:username/solved", userHandler.GetSolvedProblems)
+	api.GET("/users/:username/topics", userHandler.GetTopics, topicsLimiter)
+	api.POST("/users/:username/sync", userHandler.SyncUser, auth, owner, RequireScope(services.ScopeSyncWrite))
 
 	// Goal Routes
-	api.GET("/goals/current", goalHandler.GetCurrentGoals)
-	api.POST("/users/:username/goals/generate", goalHandler.GenerateGoals)
+	api.GET("/goals/current", goalHandler.GetCurrentGoals, auth, RequireScope(services.ScopeGoalsRead), RequireVerifiedLeetCode)
+	api.POST("/users/:username/goals/generate", goalHandler.GenerateGoals, auth, owner, RequireScope(services.ScopeGoalsWrite), RequireVerifiedLeetCode)
+
+	// Current User Routes (caller resolved from the token or API key)
+	me := api.Group("/me", auth)
+	me.GET("", userHandler.GetMe, RequireScope(services.ScopeProfileRead))
+	me.PATCH("", accountHandler.UpdateProfile, RequireSession)
+	me.DELETE("", accountHandler.DeleteAccount, RequireSession)
+	me.POST("/password", accountHandler.ChangePassword, RequireSession)
+	me.GET("/export", exportHandler.Export, RequireSession)
+	me.GET("/exports/:id", exportHandler.GetExport, RequireSession)
+	me.POST("/sync", userHandler.SyncUser, RequireScope(services.ScopeSyncWrite))
+	me.GET("/sync-events", userHandler.ListSyncEvents, RequireScope(services.ScopeProfileRead))
+	me.POST("/email-verification", authHandler.ResendEmailVerification, RequireSession)
+	me.POST("/2fa/enroll", authHandler.EnrollTwoFactor, RequireSession)
+	me.POST("/2fa/confirm", authHandler.ConfirmTwoFactor, RequireSession)
+	me.POST("/2fa/recovery-codes", authHandler.RegenerateRecoveryCodes, RequireSession)
+	me.POST("/2fa/disable", authHandler.DisableTwoFactor, RequireSession)
+	me.GET("/api-keys", authHandler.ListAPIKeys, RequireSession)
+	me.POST("/api-keys", authHandler.CreateAPIKey, RequireSession)
+	me.DELETE("/api-keys/:id", authHandler.RevokeAPIKey, RequireSession)
+	me.POST("/leetcode-verification", userHandler.StartLeetCodeVerification, RequireSession)
+	me.POST("/leetcode-verification/confirm", userHandler.ConfirmLeetCodeVerification, RequireSession)
+	me.GET("/goals", goalHandler.GetCurrentGoals, RequireScope(services.ScopeGoalsRead), RequireVerifiedLeetCode)
+	me.GET("/goals/history", goalHandler.GetGoalHistory, RequireScope(services.ScopeGoalsRead), RequireVerifiedLeetCode)
+	me.POST("/goals/generate", goalHandler.GenerateGoals, RequireScope(services.ScopeGoalsWrite), RequireVerifiedLeetCode)
+	me.GET("/goal-preferences", goalHandler.GetPreferences, RequireScope(services.ScopeGoalsRead))
+	me.PUT("/goal-preferences", goalHandler.UpdatePreferences, RequireScope(services.ScopeGoalsWrite))
+	me.DELETE("/goal-preferences", goalHandler.DeletePreferences, RequireScope(services.ScopeGoalsWrite))
+
+	// Export Downloads (authorized by the signed link itself)
+	api.GET("/exports/:id/download", exportHandler.Download)
 
 	// Comparison Routes
-	api.POST("/compare", comparisonHandler.CompareUsers)
+	api.POST("/compare", comparisonHandler.CompareUsers, auth, RequireScope(services.ScopeCompareWrite), RequireVerifiedLeetCode)
+
+	// Job Routes
+	api.GET("/jobs/:id", jobHandler.GetJob, auth)
+
+	// Admin Routes
+	admin := api.Group("/admin", auth, RequireSession, RequireRole(models.RoleAdmin))
+	admin.GET("/users", adminHandler.ListUsers)
+	admin.POST("/users/:username/sync", adminHandler.SyncUser)
+	admin.POST("/users/:username/goals/generate", adminHandler.RegenerateGoals)
+	admin.PATCH("/users/:id/role", adminHandler.SetRole)
+	admin.DELETE("/users/:id", adminHandler.DeleteUser)
+	admin.POST("/users/:id/restore", adminHandler.RestoreUser)
+	admin.DELETE("/comparisons", adminHandler.PurgeComparisons)
 
 	// Health Check
 	e.GET("/health", HealthCheck)
@@ -1,18 +1,27 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/services"
 	"github.com/labstack/echo/v4"
 )
 
 type UserHandler struct {
-	UserService *services.UserService
+	UserService  *services.UserService
+	TopicService *services.TopicService
+	JobService   *services.JobService
 }
 
-func NewUserHandler(userService *services.UserService) *UserHandler {
-	return &UserHandler{UserService: userService}
+func NewUserHandler(userService *services.UserService, topicService *services.TopicService, jobService *services.JobService) *UserHandler {
+	return &UserHandler{
+		UserService:  userService,
+		TopicService: topicService,
+		JobService:   jobService,
+	}
 }
 
 func (h *UserHandler) GetUser(c echo.Context) error {
@@ -30,14 +39,191 @@ func (h *UserHandler) GetUser(c echo.Context) error {
 	return c.JSON(http.StatusOK, user)
 }
 
+// GetHistory returns a time series of one stat for charting progress.
+// Query: from, to (RFC3339 or YYYY-MM-DD), metric (default total_solved, or topic:<tag-slug>).
+func (h *UserHandler) GetHistory(c echo.Context) error {
+	from, err := parseHistoryBound(c.QueryParam("from"), false)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid from, use RFC3339 or YYYY-MM-DD"})
+	}
+	to, err := parseHistoryBound(c.QueryParam("to"), true)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid to, use RFC3339 or YYYY-MM-DD"})
+	}
+	if !from.IsZero() && !to.IsZero() && from.After(to) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "from must be before to"})
+	}
+
+	metric := c.QueryParam("metric")
+	points, err := h.UserService.GetHistory(c.Request().Context(), c.Param("username"), metric, from, to)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUnknownMetric):
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		case errors.Is(err, services.ErrUserNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "User not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	if metric == "" {
+		metric = "total_solved"
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"metric": metric,
+		"points": points,
+	})
+}
+
+// GetHeatmap returns daily submission counts for a heatmap.
+// Query: year (e.g. 2024), or from/to (RFC3339 or YYYY-MM-DD). Defaults to the last 365 days.
+func (h *UserHandler) GetHeatmap(c echo.Context) error {
+	from, err := parseHistoryBound(c.QueryParam("from"), false)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid from, use RFC3339 or YYYY-MM-DD"})
+	}
+	to, err := parseHistoryBound(c.QueryParam("to"), true)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid to, use RFC3339 or YYYY-MM-DD"})
+	}
+	if year := c.QueryParam("year"); year != "" {
+		y, err := strconv.Atoi(year)
+		if err != nil || y < 2015 || y > 9999 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid year"})
+		}
+		from = time.Date(y, time.January, 1, 0, 0, 0, 0, time.UTC)
+		to = time.Date(y, time.December, 31, 0, 0, 0, 0, time.UTC)
+	}
+	if !from.IsZero() && !to.IsZero() && from.After(to) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "from must be before to"})
+	}
+
+	heatmap, err := h.UserService.GetHeatmap(c.Request().Context(), c.Param("username"), from, to)
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "User not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, heatmap)
+}
+
+// GetActivityStats returns streaks, active days per month and the weekday distribution
+func (h *UserHandler) GetActivityStats(c echo.Context) error {
+	stats, err := h.UserService.GetActivityStats(c.Request().Context(), c.Param("username"))
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "User not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, stats)
+}
+
+// GetSolvedProblems lists the problems recorded as solved, most recent first. Query: page, limit.
+func (h *UserHandler) GetSolvedProblems(c echo.Context) error {
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+
+	solved, err := h.UserService.GetSolvedProblems(c.Request().Context(), c.Param("username"), page, limit)
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "User not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, solved)
+}
+
+// GetTopics returns the user's topic mastery with the weakest and strongest topics
+func (h *UserHandler) GetTopics(c echo.Context) error {
+	report, err := h.TopicService.GetTopics(c.Request().Context(), c.Param("username"))
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "User not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+// parseHistoryBound accepts RFC3339 timestamps or plain dates. A plain "to" date covers the whole day.
+func parseHistoryBound(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return t, nil
+}
+
+// GetMe returns the authenticated user's profile
+func (h *UserHandler) GetMe(c echo.Context) error {
+	return c.JSON(http.StatusOK, CurrentUser(c))
+}
+
+// SyncUser queues a refresh of the authenticated user's LeetCode data and returns 202 with the job ID.
+// Served for both /users/:username/sync (guarded by RequireOwner) and /me/sync.
 func (h *UserHandler) SyncUser(c echo.Context) error {
-	username := c.Param("username")
-	ctx := c.Request().Context()
+	job, err := h.JobService.EnqueueSync(c.Request().Context(), CurrentUser(c))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to queue sync"})
+	}
+
+	return jobAccepted(c, job)
+}
 
-	user, err := h.UserService.SyncUser(ctx, username)
+// ListSyncEvents returns what changed on the caller's recent syncs, newest first.
+// Query: since (RFC3339 or YYYY-MM-DD), limit (default 20, max 100).
+func (h *UserHandler) ListSyncEvents(c echo.Context) error {
+	since, err := parseHistoryBound(c.QueryParam("since"), false)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid since, use RFC3339 or YYYY-MM-DD"})
+	}
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+
+	events, err := h.UserService.ListSyncEvents(c.Request().Context(), CurrentUser(c), since, limit)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
+	return c.JSON(http.StatusOK, events)
+}
+
+// StartLeetCodeVerification issues a token to paste into the LeetCode profile "about" section
+func (h *UserHandler) StartLeetCodeVerification(c echo.Context) error {
+	challenge, err := h.UserService.StartLeetCodeVerification(c.Request().Context(), CurrentUser(c))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to start verification"})
+	}
+
+	return c.JSON(http.StatusOK, challenge)
+}
+
+// ConfirmLeetCodeVerification checks the LeetCode profile for the issued token
+func (h *UserHandler) ConfirmLeetCodeVerification(c echo.Context) error {
+	user, err := h.UserService.ConfirmLeetCodeVerification(c.Request().Context(), CurrentUser(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrVerificationNotStarted), errors.Is(err, services.ErrVerificationExpired):
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		case errors.Is(err, services.ErrVerificationTokenNotFound):
+			return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusBadGateway, map[string]string{"error": err.Error()})
+	}
+
 	return c.JSON(http.StatusOK, user)
 }
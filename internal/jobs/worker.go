@@ -0,0 +1,183 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/config"
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/models"
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/repository"
+	"github.com/google/uuid"
+)
+
+// Handler runs one job and returns a JSON-serialisable result
+type Handler func(ctx context.Context, job *models.Job) (interface{}, error)
+
+// permanentError marks failures that retrying cannot fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so the job is dead-lettered immediately instead of retried
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// Worker claims jobs from the queue and dispatches them to the handler registered for their type
+type Worker struct {
+	Repo         repository.JobRepository
+	ID           string
+	Concurrency  int
+	PollInterval time.Duration
+	Timeout      time.Duration // Per-attempt limit
+	RetryBase    time.Duration
+	RetryMax     time.Duration
+
+	handlers map[string]Handler
+}
+
+func NewWorker(repo repository.JobRepository, cfg *config.Config) *Worker {
+	hostname, _ := os.Hostname()
+	concurrency := cfg.JobWorkers
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &Worker{
+		Repo:         repo,
+		ID:           fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), uuid.NewString()[:8]),
+		Concurrency:  concurrency,
+		PollInterval: cfg.JobPollInterval,
+		Timeout:      cfg.JobTimeout,
+		RetryBase:    cfg.JobRetryBase,
+		RetryMax:     cfg.JobRetryMax,
+		handlers:     make(map[string]Handler),
+	}
+}
+
+// Register sets the handler for a job type. It must be called before Run.
+func (w *Worker) Register(jobType string, handler Handler) {
+	w.handlers[jobType] = handler
+}
+
+// Run processes jobs with Concurrency goroutines until ctx is cancelled
+func (w *Worker) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < w.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.loop(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+func (w *Worker) loop(ctx context.Context) {
+	for ctx.Err() == nil {
+		job, err := w.Repo.Claim(ctx, w.ID, time.Now())
+		if err != nil {
+			log.Printf("Failed to claim job: %v", err)
+		}
+		if job == nil {
+			// Nothing runnable (or the claim failed), wait before polling again
+			select {
+			case <-ctx.Done():
+			case <-time.After(w.PollInterval):
+			}
+			continue
+		}
+
+		w.process(ctx, job)
+	}
+}
+
+func (w *Worker) process(ctx context.Context, job *models.Job) {
+	handler, ok := w.handlers[job.Type]
+	if !ok {
+		w.bury(ctx, job, fmt.Sprintf("no handler registered for job type %q", job.Type))
+		return
+	}
+	// A stale job released after its last attempt crashed the worker
+	if job.Attempts > job.MaxAttempts {
+		w.bury(ctx, job, "exceeded max attempts")
+		return
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, w.Timeout)
+	result, err := w.run(runCtx, handler, job)
+	cancel()
+
+	if err == nil {
+		encoded, err := json.Marshal(result)
+		if err != nil {
+			w.bury(ctx, job, fmt.Sprintf("failed to encode result: %v", err))
+			return
+		}
+		if err := w.Repo.Complete(ctx, job.ID, w.ID, encoded); err != nil {
+			w.logOutcomeFailure(job, "complete", err)
+		}
+		return
+	}
+
+	var permanent *permanentError
+	if errors.As(err, &permanent) || job.Attempts >= job.MaxAttempts {
+		w.bury(ctx, job, err.Error())
+		return
+	}
+
+	runAt := time.Now().Add(w.backoff(job.Attempts))
+	if retryErr := w.Repo.Retry(ctx, job.ID, w.ID, err.Error(), runAt); retryErr != nil {
+		w.logOutcomeFailure(job, "reschedule", retryErr)
+		return
+	}
+	log.Printf("Job %s (%s) attempt %d failed, retrying at %s: %v", job.ID, job.Type, job.Attempts, runAt.Format(time.RFC3339), err)
+}
+
+// run calls the handler, turning a panic into a regular failure
+func (w *Worker) run(ctx context.Context, handler Handler, job *models.Job) (result interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return handler(ctx, job)
+}
+
+func (w *Worker) bury(ctx context.Context, job *models.Job, errMsg string) {
+	if err := w.Repo.Bury(ctx, job.ID, w.ID, errMsg); err != nil {
+		w.logOutcomeFailure(job, "dead-letter", err)
+		return
+	}
+	log.Printf("Job %s (%s) moved to dead letter after %d attempts: %s", job.ID, job.Type, job.Attempts, errMsg)
+}
+
+// logOutcomeFailure reports an outcome that was not saved. A lost lock means the job was
+// requeued as stale and belongs to another worker now, so this worker's outcome is dropped.
+func (w *Worker) logOutcomeFailure(job *models.Job, action string, err error) {
+	if errors.Is(err, repository.ErrJobLockLost) {
+		log.Printf("Job %s (%s) was released while running here and is no longer ours, not trying to %s it", job.ID, job.Type, action)
+		return
+	}
+	log.Printf("Failed to %s job %s: %v", action, job.ID, err)
+}
+
+// backoff doubles the delay after every failed attempt, up to RetryMax
+func (w *Worker) backoff(attempts int) time.Duration {
+	delay := w.RetryBase
+	for i := 1; i < attempts && delay < w.RetryMax; i++ {
+		delay *= 2
+	}
+	if delay > w.RetryMax {
+		delay = w.RetryMax
+	}
+	return delay
+}
@@ -6,10 +6,18 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	ActivityTypeProblemSolved = "PROBLEM_SOLVED"
+	ActivityTypeContestRank   = "CONTEST_RANK"
+	ActivityTypeGoalCompleted = "GOAL_COMPLETED"
+	ActivityTypeGoalFailed    = "GOAL_FAILED"
+)
+
+// ActivityLog entries are unique per user, type, reference and timestamp so re-syncs do not duplicate them
 type ActivityLog struct {
 	ID           uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
-	UserID       uuid.UUID `gorm:"type:uuid;not null" json:"user_id"`
-	ActivityType string    `json:"activity_type"` // 'PROBLEM_SOLVED', 'CONTEST_RANK'
-	ReferenceID  string    `json:"reference_id"`
-	Timestamp    time.Time `json:"timestamp"`
+	UserID       uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_activity_logs_dedupe,priority:1" json:"user_id"`
+	ActivityType string    `gorm:"uniqueIndex:idx_activity_logs_dedupe,priority:2" json:"activity_type"` // 'PROBLEM_SOLVED', 'CONTEST_RANK', 'GOAL_COMPLETED', 'GOAL_FAILED'
+	ReferenceID  string    `gorm:"uniqueIndex:idx_activity_logs_dedupe,priority:3" json:"reference_id"`  // Problem title slug for PROBLEM_SOLVED, goal ID for GOAL_*
+	Timestamp    time.Time `gorm:"uniqueIndex:idx_activity_logs_dedupe,priority:4" json:"timestamp"`
 }
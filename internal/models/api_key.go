@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// APIKey is a named, scoped credential for scripts and bots.
+// Only a hash of the key is stored; the prefix is kept in clear text for lookup and display.
+type APIKey struct {
+	ID         uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID     uuid.UUID      `gorm:"type:uuid;not null;index" json:"user_id"`
+	Name       string         `gorm:"not null;size:100" json:"name"`
+	Prefix     string         `gorm:"uniqueIndex;not null;size:16" json:"prefix"`
+	KeyHash    string         `gorm:"not null" json:"-"`
+	Scopes     datatypes.JSON `gorm:"type:jsonb;not null" json:"scopes"` // JSON: ["sync:write", "goals:read"]
+	LastUsedAt *time.Time     `json:"last_used_at"`
+	ExpiresAt  *time.Time     `json:"expires_at"`
+	RevokedAt  *time.Time     `json:"revoked_at"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+// TableName overrides the default table name
+func (APIKey) TableName() string {
+	return "api_keys"
+}
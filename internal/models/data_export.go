@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ExportStatusPending   = "PENDING"
+	ExportStatusRunning   = "RUNNING"
+	ExportStatusCompleted = "COMPLETED"
+	ExportStatusFailed    = "FAILED"
+)
+
+// DataExport tracks an asynchronously generated personal data archive
+type DataExport struct {
+	ID          uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID      uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	Status      string     `gorm:"not null;default:'PENDING'" json:"status"` // 'PENDING', 'RUNNING', 'COMPLETED', 'FAILED'
+	FilePath    string     `json:"-"`
+	SizeBytes   int64      `json:"size_bytes"`
+	Error       string     `json:"error,omitempty"`
+	CompletedAt *time.Time `json:"completed_at"`
+	ExpiresAt   *time.Time `json:"expires_at"` // The archive and its download link stop working after this
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// TableName overrides the default table name
+func (DataExport) TableName() string {
+	return "data_exports"
+}
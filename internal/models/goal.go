@@ -7,6 +7,14 @@ import (
 	"gorm.io/datatypes"
 )
 
+const (
+	GoalStatusPending   = "PENDING"
+	GoalStatusCompleted = "COMPLETED"
+	GoalStatusFailed    = "FAILED"
+
+	GoalTypeGenerated = "GENERATED"
+)
+
 type GoalDefinition struct {
 	ID                  uint   `gorm:"primaryKey" json:"id"`
 	Type                string `gorm:"not null" json:"type"` // e.g., 'SOLVE_PROBLEMS'
@@ -15,16 +23,23 @@ type GoalDefinition struct {
 }
 
 type WeeklyGoal struct {
-	ID                  uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
-	UserID              uuid.UUID      `gorm:"type:uuid;not null" json:"user_id"`
-	User                User           `gorm:"foreignKey:UserID" json:"-"`
-	WeekStartDate       time.Time      `gorm:"not null" json:"week_start_date"`
-	GoalType            string         `gorm:"not null;default:'GENERATED'" json:"goal_type"`
-	DifficultyBreakdown datatypes.JSON `json:"difficulty_breakdown"` // JSON: {easy: 3, medium: 4, hard: 1}
-	SelectedProblems    datatypes.JSON `json:"selected_problems"`    // JSON: List of problem IDs/details
-	FocusTopics         datatypes.JSON `json:"focus_topics"`         // JSON: ["DP", "Graph"]
+	ID     uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_weekly_goals_user_week_type" json:"user_id"`
+	User   User      `gorm:"foreignKey:UserID" json:"-"`
+	// A user has at most one goal per week and type. The week is the date of its first day,
+	// in WeekTimezone.
+	WeekStartDate       time.Time      `gorm:"type:date;not null;uniqueIndex:idx_weekly_goals_user_week_type" json:"week_start_date"`
+	WeekTimezone        string         `gorm:"size:64;not null;default:'UTC'" json:"week_timezone"`
+	GoalType            string         `gorm:"not null;default:'GENERATED';uniqueIndex:idx_weekly_goals_user_week_type" json:"goal_type"`
+	Strategy            string         `gorm:"size:30;not null;default:'balanced'" json:"strategy"` // Planner that generated the goal
+	DifficultyBreakdown datatypes.JSON `json:"difficulty_breakdown"`                                // JSON: {easy: 3, medium: 4, hard: 1}
+	SelectedProblems    datatypes.JSON `json:"selected_problems"`                                   // JSON: GoalProblemSet, older rows hold a map of days to problems
+	FocusTopics         datatypes.JSON `json:"focus_topics"`                                        // JSON: ["dynamic-programming", "graph"] (tag slugs)
 	CompletionPercent   float64        `gorm:"default:0" json:"completion_percent"`
+	CompletedProblems   int            `gorm:"default:0" json:"completed_problems"`
+	TotalProblems       int            `gorm:"default:0" json:"total_problems"`
 	Status              string         `gorm:"default:'PENDING'" json:"status"` // 'PENDING', 'COMPLETED', 'FAILED'
+	FinalizedAt         *time.Time     `json:"finalized_at"`                    // Set when the week ended and Status was decided
 	CreatedAt           time.Time      `json:"created_at"`
 	UpdatedAt           time.Time      `json:"updated_at"`
 }
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// GoalProblemSetVersion is the schema version written to WeeklyGoal.SelectedProblems
+const GoalProblemSetVersion = 1
+
+// GoalProblemSet is the JSON stored in WeeklyGoal.SelectedProblems:
+// {"version": 1, "problems": [{frontend_id, title, title_slug, difficulty, topics, ac_rate, day, completed, completed_at}]}
+//
+// Rows written before versioning hold a map of day names to problems, or to plain titles
+// in the oldest goals. ParseGoalProblemSet reads all of them.
+type GoalProblemSet struct {
+	Version  int           `json:"version"`
+	Problems []GoalProblem `json:"problems"` // By day in the goal's week, then in planned order
+}
+
+// GoalProblem is one problem of a weekly goal. Problems from legacy rows may lack
+// everything but the title until the goal_problem_sets migration has run.
+type GoalProblem struct {
+	FrontendID  string     `json:"frontend_id"`
+	Title       string     `json:"title"`
+	TitleSlug   string     `json:"title_slug"`
+	Difficulty  string     `json:"difficulty"` // "Easy", "Medium" or "Hard"
+	Topics      []string   `json:"topics"`     // Tag slugs
+	AcRate      float64    `json:"ac_rate"`
+	Day         string     `json:"day"` // Day of the week it is planned on, e.g. "Monday"
+	Completed   bool       `json:"completed"`
+	CompletedAt *time.Time `json:"completed_at"`
+}
+
+// NewGoalProblemSet returns the current version of a problem set, with problems ordered
+// by day from weekStart's weekday. Problems of the same day keep their order.
+func NewGoalProblemSet(problems []GoalProblem, weekStart time.Time) GoalProblemSet {
+	ordered := make([]GoalProblem, len(problems))
+	copy(ordered, problems)
+	for i := range ordered {
+		if ordered[i].Topics == nil {
+			ordered[i].Topics = []string{}
+		}
+	}
+	first := weekStart.Weekday()
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return dayOffset(ordered[i].Day, first) < dayOffset(ordered[j].Day, first)
+	})
+	return GoalProblemSet{Version: GoalProblemSetVersion, Problems: ordered}
+}
+
+// ParseGoalProblemSet reads SelectedProblems in any version. Legacy rows come back as the
+// current version ordered from Monday; NewGoalProblemSet reorders them for another week.
+func ParseGoalProblemSet(raw datatypes.JSON) (GoalProblemSet, error) {
+	set := GoalProblemSet{Version: GoalProblemSetVersion, Problems: []GoalProblem{}}
+	if len(raw) == 0 || bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+		return set, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return set, fmt.Errorf("invalid selected problems: %w", err)
+	}
+
+	if _, ok := fields["version"]; ok {
+		if err := json.Unmarshal(raw, &set); err != nil {
+			return set, fmt.Errorf("invalid selected problems: %w", err)
+		}
+		if set.Version > GoalProblemSetVersion {
+			return set, fmt.Errorf("unsupported selected problems version %d", set.Version)
+		}
+		if set.Problems == nil {
+			set.Problems = []GoalProblem{}
+		}
+		for i := range set.Problems {
+			if set.Problems[i].Topics == nil {
+				set.Problems[i].Topics = []string{}
+			}
+		}
+		return set, nil
+	}
+
+	// Legacy: {"Monday": [{title, title_slug, difficulty, completed, completed_at}]} or {"Monday": ["Two Sum"]}
+	var problems []GoalProblem
+	for day, value := range fields {
+		var entries []json.RawMessage
+		if err := json.Unmarshal(value, &entries); err != nil {
+			return set, fmt.Errorf("invalid selected problems: %w", err)
+		}
+		for _, entry := range entries {
+			var problem GoalProblem
+			if bytes.HasPrefix(bytes.TrimSpace(entry), []byte(`"`)) {
+				if err := json.Unmarshal(entry, &problem.Title); err != nil {
+					return set, fmt.Errorf("invalid selected problem: %w", err)
+				}
+			} else if err := json.Unmarshal(entry, &problem); err != nil {
+				return set, fmt.Errorf("invalid selected problem: %w", err)
+			}
+			problem.Day = day
+			problems = append(problems, problem)
+		}
+	}
+	// Map order is random, so order unknown day names too
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].Day < problems[j].Day })
+	return NewGoalProblemSet(problems, legacyWeekStart), nil
+}
+
+// legacyWeekStart is any Monday
+var legacyWeekStart = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// dayOffset is how many days after first the named day falls, or 7 for unknown names
+func dayOffset(day string, first time.Weekday) int {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if d.String() == day {
+			return (int(d) - int(first) + 7) % 7
+		}
+	}
+	return 7
+}
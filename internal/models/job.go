@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+const (
+	JobStatusQueued    = "QUEUED"
+	JobStatusRunning   = "RUNNING"
+	JobStatusSucceeded = "SUCCEEDED"
+	JobStatusDead      = "DEAD" // Gave up after the last attempt or a permanent error
+)
+
+const (
+	JobTypeUserSync      = "user.sync"
+	JobTypeGoalsGenerate = "goals.generate"
+	JobTypeComparisonRun = "comparison.run"
+)
+
+// Job is a unit of background work in the Postgres-backed queue
+type Job struct {
+	ID          uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Type        string         `gorm:"not null;size:50;index" json:"type"`
+	Status      string         `gorm:"not null;default:'QUEUED';size:20;index:idx_jobs_status_run_at,priority:1" json:"status"` // 'QUEUED', 'RUNNING', 'SUCCEEDED', 'DEAD'
+	UserID      *uuid.UUID     `gorm:"type:uuid;index" json:"user_id"`                                                          // Owner, who may read the job's status and result
+	Payload     datatypes.JSON `gorm:"type:jsonb" json:"-"`
+	Result      datatypes.JSON `gorm:"type:jsonb" json:"result,omitempty"`
+	Error       string         `gorm:"type:text" json:"error,omitempty"` // Last failure, kept while retrying
+	Attempts    int            `gorm:"not null;default:0" json:"attempts"`
+	MaxAttempts int            `gorm:"not null;default:5" json:"max_attempts"`
+	RunAt       time.Time      `gorm:"not null;index:idx_jobs_status_run_at,priority:2" json:"run_at"` // Not picked up before this (retry backoff)
+	LockedBy    string         `gorm:"size:100" json:"-"`
+	LockedAt    *time.Time     `json:"-"`
+	FinishedAt  *time.Time     `json:"finished_at"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+// TableName overrides the default table name
+func (Job) TableName() string {
+	return "jobs"
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LoginAttempt counts consecutive failed logins for a key (a user, an unknown identifier or an IP)
+type LoginAttempt struct {
+	Key           string     `gorm:"primaryKey;size:320" json:"key"`
+	Failures      int        `gorm:"not null;default:0" json:"failures"`
+	LastFailureAt time.Time  `json:"last_failure_at"`
+	LockedUntil   *time.Time `json:"locked_until"`
+}
+
+// TableName overrides the default table name
+func (LoginAttempt) TableName() string {
+	return "login_attempts"
+}
+
+// FailedLogin is an audit record of a rejected login attempt
+type FailedLogin struct {
+	ID         uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID     *uuid.UUID `gorm:"type:uuid;index" json:"user_id"` // Nil when the identifier matched no user
+	Identifier string     `gorm:"size:255" json:"identifier"`
+	IPAddress  string     `gorm:"index" json:"ip_address"`
+	UserAgent  string     `json:"user_agent"`
+	Reason     string     `json:"reason"` // 'UNKNOWN_USER', 'BAD_PASSWORD', 'BAD_2FA_CODE', 'LOCKED'
+	CreatedAt  time.Time  `gorm:"index" json:"created_at"`
+}
+
+// TableName overrides the default table name
+func (FailedLogin) TableName() string {
+	return "failed_logins"
+}
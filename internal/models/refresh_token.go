@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefreshToken is a single-use token that can be exchanged for a new access token.
+// Every rotation issues a new row in the same family, so a family represents one login session.
+type RefreshToken struct {
+	ID               uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID           uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	FamilyID         uuid.UUID  `gorm:"type:uuid;not null;index" json:"family_id"`
+	TokenHash        string     `gorm:"uniqueIndex;not null" json:"-"`
+	UserAgent        string     `json:"user_agent"`
+	IPAddress        string     `json:"ip_address"`
+	SessionStartedAt time.Time  `gorm:"not null" json:"session_started_at"`
+	ExpiresAt        time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt           *time.Time `json:"used_at"` // Set when the token is rotated
+	RevokedAt        *time.Time `json:"revoked_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// TableName overrides the default table name
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// SyncEvent records what changed in a user's LeetCode stats during one sync
+type SyncEvent struct {
+	ID        uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID    uuid.UUID      `gorm:"type:uuid;not null;index:idx_sync_events_user_time,priority:1" json:"user_id"`
+	Diff      datatypes.JSON `gorm:"type:jsonb;not null" json:"diff"` // services.SyncDiff
+	CreatedAt time.Time      `gorm:"index:idx_sync_events_user_time,priority:2" json:"created_at"`
+}
+
+// TableName overrides the default table name
+func (SyncEvent) TableName() string {
+	return "sync_events"
+}
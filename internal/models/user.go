@@ -8,6 +8,11 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 // User represents the comprehensive user data model
 type User struct {
 	ID        uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
@@ -20,7 +25,35 @@ type User struct {
 	// ==========================================
 	Username     string `gorm:"uniqueIndex;not null;size:50" json:"username"`
 	Email        string `gorm:"uniqueIndex;not null;size:255" json:"email"`
-	PasswordHash string `gorm:"not null" json:"-"` // Never return password hash in JSON
+	PasswordHash string `gorm:"not null" json:"-"`                           // Never return password hash in JSON
+	Role         string `gorm:"not null;default:'user';size:20" json:"role"` // 'user', 'admin'
+
+	// Set when the user deletes their account; data is purged after this time unless restored
+	DeletionScheduledAt *time.Time `json:"deletionScheduledAt,omitempty"`
+
+	EmailVerified   bool       `gorm:"not null;default:false" json:"emailVerified"`
+	EmailVerifiedAt *time.Time `json:"emailVerifiedAt"`
+
+	// Two-factor authentication (TOTP). The secret is set on enrollment and only
+	// enforced once the user confirms it with a valid code.
+	TwoFactorEnabled bool   `gorm:"not null;default:false" json:"twoFactorEnabled"`
+	TOTPSecret       string `json:"-"`
+	TOTPLastUsedStep int64  `json:"-"` // Rejects replay of an already used code
+
+	// ==========================================
+	// LeetCode Ownership Verification
+	// ==========================================
+	LeetCodeVerified   bool       `gorm:"not null;default:false" json:"leetCodeVerified"`
+	LeetCodeVerifiedAt *time.Time `json:"leetCodeVerifiedAt"`
+	// Token the user pastes into their LeetCode "about" field to prove ownership
+	VerificationToken          string     `json:"-"`
+	VerificationTokenExpiresAt *time.Time `json:"-"`
+
+	// ==========================================
+	// Background Sync
+	// ==========================================
+	LastSyncedAt *time.Time `json:"lastSyncedAt"`
+	NextSyncAt   *time.Time `gorm:"index" json:"nextSyncAt"` // When the scheduler will next refresh this user
 
 	// ==========================================
 	// Basic Profile Info (from getUserProfile)
@@ -83,6 +116,14 @@ type User struct {
 	// Submission Calendar (Heatmap)
 	SubmissionCalendar datatypes.JSON `gorm:"type:jsonb" json:"submissionCalendar"`
 
+	// ==========================================
+	// App-Specific Profile
+	// ==========================================
+	DisplayName  string `gorm:"size:100" json:"displayName"`
+	GoalStrategy string `gorm:"size:30;not null;default:'balanced'" json:"goalStrategy"` // Planner used when generating weekly goals
+	Timezone     string `gorm:"size:64;not null;default:'UTC'" json:"timezone"`          // IANA zone goal weeks are computed in
+	WeekStartDay string `gorm:"size:10;not null;default:'Monday'" json:"weekStartDay"`   // First day of the user's goal week
+
 	// ==========================================
 	// App-Specific Calculated Score
 	// ==========================================
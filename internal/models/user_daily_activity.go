@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserDailyActivity is one day of a user's LeetCode submission calendar
+type UserDailyActivity struct {
+	UserID      uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
+	Date        time.Time `gorm:"type:date;primaryKey" json:"date"` // UTC day, as bucketed by LeetCode
+	Submissions int       `gorm:"not null;default:0" json:"submissions"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TableName overrides the default table name
+func (UserDailyActivity) TableName() string {
+	return "user_daily_activity"
+}
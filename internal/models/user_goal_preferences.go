@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// UserGoalPreferences tunes weekly goal generation for one user
+type UserGoalPreferences struct {
+	ID             uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID         uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex" json:"user_id"`
+	TargetProblems int            `gorm:"not null;default:0" json:"target_problems"` // Problems per week, 0 uses the strategy's count
+	Difficulties   datatypes.JSON `gorm:"type:jsonb" json:"difficulties"`            // JSON: ["Easy", "Medium"], empty allows all
+	IncludeTopics  datatypes.JSON `gorm:"type:jsonb" json:"include_topics"`          // JSON: tag slugs to focus on
+	ExcludeTopics  datatypes.JSON `gorm:"type:jsonb" json:"exclude_topics"`          // JSON: tag slugs never assigned
+	RestDays       datatypes.JSON `gorm:"type:jsonb" json:"rest_days"`               // JSON: ["Saturday", "Sunday"]
+	DailyMinutes   datatypes.JSON `gorm:"type:jsonb" json:"daily_minutes"`           // JSON: {"Monday": 30}, missing days are unlimited
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+// TableName overrides the default table name
+func (UserGoalPreferences) TableName() string {
+	return "user_goal_preferences"
+}
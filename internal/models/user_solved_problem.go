@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserSolvedProblem is a problem the user has an accepted submission for, one row per problem
+type UserSolvedProblem struct {
+	ID            uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID        uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_user_solved_problems_user_slug,priority:1" json:"user_id"`
+	TitleSlug     string    `gorm:"not null;size:200;uniqueIndex:idx_user_solved_problems_user_slug,priority:2" json:"title_slug"`
+	Title         string    `json:"title"`
+	Language      string    `gorm:"size:50" json:"language"` // Language of the latest accepted submission
+	FirstSolvedAt time.Time `json:"first_solved_at"`         // Earliest accepted submission we have seen
+	LastSolvedAt  time.Time `gorm:"index" json:"last_solved_at"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// TableName overrides the default table name
+func (UserSolvedProblem) TableName() string {
+	return "user_solved_problems"
+}
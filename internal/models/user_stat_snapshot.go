@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// UserStatSnapshot records a user's LeetCode stats as they were at one successful sync
+type UserStatSnapshot struct {
+	ID     uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID uuid.UUID `gorm:"type:uuid;not null;index:idx_user_stat_snapshots_user_time,priority:1" json:"user_id"`
+
+	TotalSolved   int     `json:"total_solved"`
+	EasySolved    int     `json:"easy_solved"`
+	MediumSolved  int     `json:"medium_solved"`
+	HardSolved    int     `json:"hard_solved"`
+	Ranking       int     `json:"ranking"`
+	ContestRating float64 `json:"contest_rating"`
+	Reputation    int     `json:"reputation"`
+	Streak        int     `json:"streak"`
+
+	// Problems solved per topic, keyed by tag slug (from SkillTags)
+	TopicCounts datatypes.JSON `gorm:"type:jsonb" json:"topic_counts"` // map[string]int
+
+	CreatedAt time.Time `gorm:"index:idx_user_stat_snapshots_user_time,priority:2" json:"created_at"`
+}
+
+// TableName overrides the default table name
+func (UserStatSnapshot) TableName() string {
+	return "user_stat_snapshots"
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	TokenPurposeEmailVerification = "EMAIL_VERIFICATION"
+	TokenPurposePasswordReset     = "PASSWORD_RESET"
+)
+
+// UserToken is a single-use, expiring token sent to the user by email
+type UserToken struct {
+	ID        uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	Purpose   string     `gorm:"not null;size:32" json:"purpose"` // 'EMAIL_VERIFICATION', 'PASSWORD_RESET'
+	TokenHash string     `gorm:"uniqueIndex;not null" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TableName overrides the default table name
+func (UserToken) TableName() string {
+	return "user_tokens"
+}
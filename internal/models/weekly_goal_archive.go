@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// WeeklyGoalArchive keeps a copy of a weekly goal removed by retention cleanup,
+// so goal history and stats outlive the live weekly_goals window
+type WeeklyGoalArchive struct {
+	ID                  uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"` // Same ID the goal had
+	UserID              uuid.UUID      `gorm:"type:uuid;not null;index" json:"user_id"`
+	WeekStartDate       time.Time      `gorm:"type:date;not null" json:"week_start_date"`
+	WeekTimezone        string         `gorm:"size:64;not null;default:'UTC'" json:"week_timezone"`
+	GoalType            string         `gorm:"not null" json:"goal_type"`
+	Strategy            string         `gorm:"size:30" json:"strategy"`
+	DifficultyBreakdown datatypes.JSON `json:"difficulty_breakdown"`
+	SelectedProblems    datatypes.JSON `json:"selected_problems"`
+	FocusTopics         datatypes.JSON `json:"focus_topics"`
+	CompletionPercent   float64        `json:"completion_percent"`
+	CompletedProblems   int            `json:"completed_problems"`
+	TotalProblems       int            `json:"total_problems"`
+	Status              string         `json:"status"`
+	FinalizedAt         *time.Time     `json:"finalized_at"`
+	CreatedAt           time.Time      `json:"created_at"`
+	UpdatedAt           time.Time      `json:"updated_at"`
+	ArchivedAt          time.Time      `gorm:"not null" json:"archived_at"`
+}
+
+// TableName overrides the default table name
+func (WeeklyGoalArchive) TableName() string {
+	return "weekly_goal_archives"
+}
+
+// NewWeeklyGoalArchive copies goal into an archive row
+func NewWeeklyGoalArchive(goal WeeklyGoal, archivedAt time.Time) WeeklyGoalArchive {
+	return WeeklyGoalArchive{
+		ID:                  goal.ID,
+		UserID:              goal.UserID,
+		WeekStartDate:       goal.WeekStartDate,
+		WeekTimezone:        goal.WeekTimezone,
+		GoalType:            goal.GoalType,
+		Strategy:            goal.Strategy,
+		DifficultyBreakdown: goal.DifficultyBreakdown,
+		SelectedProblems:    goal.SelectedProblems,
+		FocusTopics:         goal.FocusTopics,
+		CompletionPercent:   goal.CompletionPercent,
+		CompletedProblems:   goal.CompletedProblems,
+		TotalProblems:       goal.TotalProblems,
+		Status:              goal.Status,
+		FinalizedAt:         goal.FinalizedAt,
+		CreatedAt:           goal.CreatedAt,
+		UpdatedAt:           goal.UpdatedAt,
+		ArchivedAt:          archivedAt,
+	}
+}
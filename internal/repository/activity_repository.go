@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ActivityRepository interface {
+	// CreateIgnoringDuplicates inserts logs, skipping ones already recorded
+	CreateIgnoringDuplicates(ctx context.Context, logs []models.ActivityLog) error
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]models.ActivityLog, error)
+	CountByUser(ctx context.Context, userID uuid.UUID) (int64, error)
+	// ListByType returns the user's entries of one type with timestamps in [from, to), oldest first
+	ListByType(ctx context.Context, userID uuid.UUID, activityType string, from, to time.Time) ([]models.ActivityLog, error)
+}
+
+type activityRepository struct {
+	db *gorm.DB
+}
+
+func NewActivityRepository(db *gorm.DB) ActivityRepository {
+	return &activityRepository{db: db}
+}
+
+func (r *activityRepository) CreateIgnoringDuplicates(ctx context.Context, logs []models.ActivityLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&logs).Error
+}
+
+func (r *activityRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]models.ActivityLog, error) {
+	var logs []models.ActivityLog
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("timestamp").Find(&logs).Error
+	return logs, err
+}
+
+func (r *activityRepository) ListByType(ctx context.Context, userID uuid.UUID, activityType string, from, to time.Time) ([]models.ActivityLog, error) {
+	var logs []models.ActivityLog
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND activity_type = ? AND timestamp >= ? AND timestamp < ?", userID, activityType, from, to).
+		Order("timestamp").
+		Find(&logs).Error
+	return logs, err
+}
+
+func (r *activityRepository) CountByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.ActivityLog{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *models.APIKey) error
+	GetByPrefix(ctx context.Context, prefix string) (*models.APIKey, error)
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]models.APIKey, error)
+	CountActive(ctx context.Context, userID uuid.UUID, now time.Time) (int64, error)
+	// Revoke reports false if the user has no unrevoked key with that ID
+	Revoke(ctx context.Context, userID, id uuid.UUID, at time.Time) (bool, error)
+	RevokeAllForUser(ctx context.Context, userID uuid.UUID, at time.Time) error
+	TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error
+}
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+func (r *apiKeyRepository) Create(ctx context.Context, key *models.APIKey) error {
+	return r.db.WithContext(ctx).Create(key).Error
+}
+
+func (r *apiKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*models.APIKey, error) {
+	var key models.APIKey
+	err := r.db.WithContext(ctx).First(&key, "prefix = ?", prefix).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &key, err
+}
+
+func (r *apiKeyRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").Find(&keys).Error
+	return keys, err
+}
+
+func (r *apiKeyRepository) CountActive(ctx context.Context, userID uuid.UUID, now time.Time) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.APIKey{}).
+		Where("user_id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", userID, now).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *apiKeyRepository) Revoke(ctx context.Context, userID, id uuid.UUID, at time.Time) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&models.APIKey{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", at)
+	return res.RowsAffected == 1, res.Error
+}
+
+func (r *apiKeyRepository) RevokeAllForUser(ctx context.Context, userID uuid.UUID, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.APIKey{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", at).Error
+}
+
+func (r *apiKeyRepository) TouchLastUsed(ctx context.Context, id uuid.UUID, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.APIKey{}).Where("id = ?", id).Update("last_used_at", at).Error
+}
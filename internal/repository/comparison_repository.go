@@ -46,3 +46,29 @@ func (r *ComparisonRepository) SaveComparison(user1, user2 string, result interf
 
 	return DB.Create(&comparison).Error
 }
+
+// PurgeComparisons permanently deletes cached comparisons created before the given time.
+// If username is not empty, only comparisons involving that user are removed.
+func (r *ComparisonRepository) PurgeComparisons(username string, before time.Time) (int64, error) {
+	query := DB.Unscoped().Where("created_at < ?", before)
+	if username != "" {
+		query = query.Where("user1_name = ? OR user2_name = ?", username, username)
+	}
+
+	res := query.Delete(&models.UserComparison{})
+	return res.RowsAffected, res.Error
+}
+
+// ListByUsername returns every comparison the user took part in
+func (r *ComparisonRepository) ListByUsername(username string) ([]models.UserComparison, error) {
+	var comparisons []models.UserComparison
+	err := DB.Where("user1_name = ? OR user2_name = ?", username, username).Order("created_at").Find(&comparisons).Error
+	return comparisons, err
+}
+
+// CountByUsername counts the comparisons the user took part in
+func (r *ComparisonRepository) CountByUsername(username string) (int64, error) {
+	var count int64
+	err := DB.Model(&models.UserComparison{}).Where("user1_name = ? OR user2_name = ?", username, username).Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DailyActivityRepository interface {
+	// Upsert inserts the days or overwrites their submission counts
+	Upsert(ctx context.Context, days []models.UserDailyActivity) error
+	// ListByUser returns the user's days within [from, to], oldest first. Zero bounds are open.
+	ListByUser(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]models.UserDailyActivity, error)
+	CountByUser(ctx context.Context, userID uuid.UUID) (int64, error)
+}
+
+type dailyActivityRepository struct {
+	db *gorm.DB
+}
+
+func NewDailyActivityRepository(db *gorm.DB) DailyActivityRepository {
+	return &dailyActivityRepository{db: db}
+}
+
+func (r *dailyActivityRepository) Upsert(ctx context.Context, days []models.UserDailyActivity) error {
+	if len(days) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "date"}},
+			DoUpdates: clause.AssignmentColumns([]string{"submissions", "updated_at"}),
+		}).
+		CreateInBatches(days, 500).Error
+}
+
+func (r *dailyActivityRepository) ListByUser(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]models.UserDailyActivity, error) {
+	query := r.db.WithContext(ctx).Where("user_id = ?", userID)
+	if !from.IsZero() {
+		query = query.Where("date >= ?", from)
+	}
+	if !to.IsZero() {
+		query = query.Where("date <= ?", to)
+	}
+
+	var days []models.UserDailyActivity
+	err := query.Order("date").Find(&days).Error
+	return days, err
+}
+
+func (r *dailyActivityRepository) CountByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.UserDailyActivity{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type DataExportRepository interface {
+	Create(ctx context.Context, export *models.DataExport) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.DataExport, error)
+	// GetInProgress returns the user's pending or running export, if any
+	GetInProgress(ctx context.Context, userID uuid.UUID) (*models.DataExport, error)
+	Update(ctx context.Context, export *models.DataExport) error
+	// FailStale marks pending or running exports last updated before the cutoff as failed, e.g. after a crash
+	FailStale(ctx context.Context, updatedBefore, expiresAt time.Time) (int64, error)
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]models.DataExport, error)
+	ListExpired(ctx context.Context, now time.Time) ([]models.DataExport, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type dataExportRepository struct {
+	db *gorm.DB
+}
+
+func NewDataExportRepository(db *gorm.DB) DataExportRepository {
+	return &dataExportRepository{db: db}
+}
+
+func (r *dataExportRepository) Create(ctx context.Context, export *models.DataExport) error {
+	return r.db.WithContext(ctx).Create(export).Error
+}
+
+func (r *dataExportRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.DataExport, error) {
+	var export models.DataExport
+	err := r.db.WithContext(ctx).First(&export, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &export, err
+}
+
+func (r *dataExportRepository) GetInProgress(ctx context.Context, userID uuid.UUID) (*models.DataExport, error) {
+	var export models.DataExport
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND status IN ?", userID, []string{models.ExportStatusPending, models.ExportStatusRunning}).
+		First(&export).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &export, err
+}
+
+func (r *dataExportRepository) Update(ctx context.Context, export *models.DataExport) error {
+	return r.db.WithContext(ctx).Save(export).Error
+}
+
+func (r *dataExportRepository) FailStale(ctx context.Context, updatedBefore, expiresAt time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&models.DataExport{}).
+		Where("status IN ? AND updated_at < ?", []string{models.ExportStatusPending, models.ExportStatusRunning}, updatedBefore).
+		Updates(map[string]interface{}{
+			"status":     models.ExportStatusFailed,
+			"error":      "export was interrupted",
+			"expires_at": expiresAt,
+		})
+	return result.RowsAffected, result.Error
+}
+
+func (r *dataExportRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]models.DataExport, error) {
+	var exports []models.DataExport
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Find(&exports).Error
+	return exports, err
+}
+
+func (r *dataExportRepository) ListExpired(ctx context.Context, now time.Time) ([]models.DataExport, error) {
+	var exports []models.DataExport
+	err := r.db.WithContext(ctx).Where("expires_at IS NOT NULL AND expires_at <= ?", now).Find(&exports).Error
+	return exports, err
+}
+
+func (r *dataExportRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&models.DataExport{}, "id = ?", id).Error
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"errors"
 	"log"
 
 	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/config"
@@ -20,16 +21,59 @@ func InitDB(cfg *config.Config) {
 
 	log.Println("Database connected successfully")
 
+	if err := checkWeeklyGoalDuplicates(DB); err != nil {
+		log.Fatal("Failed to migrate database: ", err)
+	}
+
 	// Auto Migrate
 	err = DB.AutoMigrate(
 		&models.User{},
 		&models.GoalDefinition{},
 		&models.WeeklyGoal{},
+		&models.WeeklyGoalArchive{},
+		&models.UserGoalPreferences{},
 		&models.ActivityLog{},
 		&models.UserComparison{},
+		&models.RefreshToken{},
+		&models.UserToken{},
+		&models.RecoveryCode{},
+		&models.LoginAttempt{},
+		&models.FailedLogin{},
+		&models.APIKey{},
+		&models.DataExport{},
+		&models.UserStatSnapshot{},
+		&models.Job{},
+		&models.SyncEvent{},
+		&models.UserDailyActivity{},
+		&models.UserSolvedProblem{},
 	)
 	if err != nil {
 		log.Fatal("Failed to migrate database: ", err)
 	}
 	log.Println("Database migration completed")
 }
+
+// checkWeeklyGoalDuplicates fails with a pointer to the dedupe migration when AutoMigrate
+// could not create the unique weekly goal index because of duplicate goals
+func checkWeeklyGoalDuplicates(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasTable(&models.WeeklyGoal{}) || migrator.HasIndex(&models.WeeklyGoal{}, "idx_weekly_goals_user_week_type") {
+		return nil
+	}
+
+	var duplicates int64
+	err := db.Raw(`
+		SELECT COUNT(*) FROM (
+			SELECT 1 FROM weekly_goals
+			GROUP BY user_id, week_start_date, goal_type
+			HAVING COUNT(*) > 1
+		) duplicated
+	`).Scan(&duplicates).Error
+	if err != nil {
+		return err
+	}
+	if duplicates > 0 {
+		return errors.New("weekly_goals holds duplicate goals for the same user, week and type; run go run cmd/migrations/dedupe_weekly_goals/main.go first")
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GoalPreferencesRepository interface {
+	// GetByUser returns the user's preferences, or nil if they never saved any
+	GetByUser(ctx context.Context, userID uuid.UUID) (*models.UserGoalPreferences, error)
+	// Upsert creates the user's preferences or replaces every field of the existing ones
+	Upsert(ctx context.Context, prefs *models.UserGoalPreferences) error
+	DeleteByUser(ctx context.Context, userID uuid.UUID) error
+}
+
+type goalPreferencesRepository struct {
+	db *gorm.DB
+}
+
+func NewGoalPreferencesRepository(db *gorm.DB) GoalPreferencesRepository {
+	return &goalPreferencesRepository{db: db}
+}
+
+func (r *goalPreferencesRepository) GetByUser(ctx context.Context, userID uuid.UUID) (*models.UserGoalPreferences, error) {
+	var prefs models.UserGoalPreferences
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&prefs).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &prefs, err
+}
+
+func (r *goalPreferencesRepository) Upsert(ctx context.Context, prefs *models.UserGoalPreferences) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"target_problems", "difficulties", "include_topics", "exclude_topics", "rest_days", "daily_minutes", "updated_at",
+			}),
+		}).
+		Create(prefs).Error
+}
+
+func (r *goalPreferencesRepository) DeleteByUser(ctx context.Context, userID uuid.UUID) error {
+	return r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&models.UserGoalPreferences{}).Error
+}
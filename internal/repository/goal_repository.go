@@ -2,16 +2,47 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/models"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// archiveBatchSize caps how many goals ArchiveAndDelete moves per transaction
+const archiveBatchSize = 500
+
 type GoalRepository interface {
 	CreateBatch(ctx context.Context, goals []models.WeeklyGoal) error
+	// CreateIfAbsent inserts the goal unless the user already has one of the same type for that week,
+	// reporting whether it was inserted
+	CreateIfAbsent(ctx context.Context, goal *models.WeeklyGoal) (bool, error)
+	// ListStartingBetween returns the user's goals for weeks starting within [from, to]
+	ListStartingBetween(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]models.WeeklyGoal, error)
+	// GetWeeklyGoal returns the user's goal of goalType for the week, or nil if there is none
+	GetWeeklyGoal(ctx context.Context, userID uuid.UUID, weekStart time.Time, goalType string) (*models.WeeklyGoal, error)
 	GetWeeklyGoals(ctx context.Context, userID uuid.UUID, weekStart time.Time) ([]models.WeeklyGoal, error)
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]models.WeeklyGoal, error)
+	// ListSince returns the user's goals for weeks starting on or after since
+	ListSince(ctx context.Context, userID uuid.UUID, since time.Time) ([]models.WeeklyGoal, error)
+	CountByUser(ctx context.Context, userID uuid.UUID) (int64, error)
+	Update(ctx context.Context, goal *models.WeeklyGoal) error
+	ListPendingByUser(ctx context.Context, userID uuid.UUID) ([]models.WeeklyGoal, error)
+	// ListPendingStartedBefore returns pending goals of any user whose week starts on a date before the cutoff, oldest first
+	ListPendingStartedBefore(ctx context.Context, cutoff time.Time, limit int) ([]models.WeeklyGoal, error)
+	// ArchiveAndDelete removes finalized goals for weeks starting before keepFrom and goals for
+	// weeks starting after keepUntil, copying them to weekly_goal_archives first when archive is
+	// set. A nil userID cleans up every user. Goals are processed in batches, one transaction each.
+	ArchiveAndDelete(ctx context.Context, userID *uuid.UUID, keepFrom, keepUntil time.Time, archive bool) (int64, error)
+	// ListArchivedSince returns the user's archived goals for weeks starting on or after since
+	ListArchivedSince(ctx context.Context, userID uuid.UUID, since time.Time) ([]models.WeeklyGoalArchive, error)
+	// ListArchived returns the user's archived goals, most recent week first
+	ListArchived(ctx context.Context, userID uuid.UUID, offset, limit int) ([]models.WeeklyGoalArchive, int64, error)
+	// ListAllArchived returns all of the user's archived goals, oldest week first
+	ListAllArchived(ctx context.Context, userID uuid.UUID) ([]models.WeeklyGoalArchive, error)
+	CountArchivedByUser(ctx context.Context, userID uuid.UUID) (int64, error)
 	CreateGoalDefinition(ctx context.Context, def *models.GoalDefinition) error
 	GetGoalDefinitions(ctx context.Context) ([]models.GoalDefinition, error)
 }
@@ -28,6 +59,34 @@ func (r *goalRepository) CreateBatch(ctx context.Context, goals []models.WeeklyG
 	return r.db.WithContext(ctx).Create(&goals).Error
 }
 
+func (r *goalRepository) CreateIfAbsent(ctx context.Context, goal *models.WeeklyGoal) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "week_start_date"}, {Name: "goal_type"}},
+			DoNothing: true,
+		}).
+		Create(goal)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *goalRepository) ListStartingBetween(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]models.WeeklyGoal, error) {
+	var goals []models.WeeklyGoal
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND week_start_date BETWEEN ? AND ?", userID, from, to).
+		Order("week_start_date").
+		Find(&goals).Error
+	return goals, err
+}
+
+func (r *goalRepository) GetWeeklyGoal(ctx context.Context, userID uuid.UUID, weekStart time.Time, goalType string) (*models.WeeklyGoal, error) {
+	var goal models.WeeklyGoal
+	err := r.db.WithContext(ctx).Where("user_id = ? AND week_start_date = ? AND goal_type = ?", userID, weekStart, goalType).First(&goal).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &goal, err
+}
+
 func (r *goalRepository) GetWeeklyGoals(ctx context.Context, userID uuid.UUID, weekStart time.Time) ([]models.WeeklyGoal, error) {
 	var goals []models.WeeklyGoal
 	// Assuming weekStart is the beginning of the week, we might want to filter by range or exact match
@@ -36,6 +95,125 @@ func (r *goalRepository) GetWeeklyGoals(ctx context.Context, userID uuid.UUID, w
 	return goals, err
 }
 
+func (r *goalRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]models.WeeklyGoal, error) {
+	var goals []models.WeeklyGoal
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("week_start_date").Find(&goals).Error
+	return goals, err
+}
+
+func (r *goalRepository) ListSince(ctx context.Context, userID uuid.UUID, since time.Time) ([]models.WeeklyGoal, error) {
+	var goals []models.WeeklyGoal
+	err := r.db.WithContext(ctx).Where("user_id = ? AND week_start_date >= ?", userID, since).Order("week_start_date").Find(&goals).Error
+	return goals, err
+}
+
+func (r *goalRepository) CountByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.WeeklyGoal{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+func (r *goalRepository) Update(ctx context.Context, goal *models.WeeklyGoal) error {
+	return r.db.WithContext(ctx).Save(goal).Error
+}
+
+func (r *goalRepository) ListPendingByUser(ctx context.Context, userID uuid.UUID) ([]models.WeeklyGoal, error) {
+	var goals []models.WeeklyGoal
+	err := r.db.WithContext(ctx).Where("user_id = ? AND status = ?", userID, models.GoalStatusPending).Find(&goals).Error
+	return goals, err
+}
+
+func (r *goalRepository) ListPendingStartedBefore(ctx context.Context, cutoff time.Time, limit int) ([]models.WeeklyGoal, error) {
+	var goals []models.WeeklyGoal
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND week_start_date < ?", models.GoalStatusPending, cutoff).
+		Order("week_start_date").
+		Limit(limit).
+		Find(&goals).Error
+	return goals, err
+}
+
+func (r *goalRepository) ArchiveAndDelete(ctx context.Context, userID *uuid.UUID, keepFrom, keepUntil time.Time, archive bool) (int64, error) {
+	var removed int64
+	for {
+		n, err := r.archiveAndDeleteBatch(ctx, userID, keepFrom, keepUntil, archive)
+		removed += n
+		if err != nil || n < archiveBatchSize {
+			return removed, err
+		}
+	}
+}
+
+func (r *goalRepository) archiveAndDeleteBatch(ctx context.Context, userID *uuid.UUID, keepFrom, keepUntil time.Time, archive bool) (int64, error) {
+	var removed int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Pending goals of past weeks wait for FinalizeEndedGoals
+		query := tx.Where("(week_start_date < ? AND status <> ?) OR week_start_date > ?", keepFrom, models.GoalStatusPending, keepUntil)
+		if userID != nil {
+			query = query.Where("user_id = ?", *userID)
+		}
+
+		var goals []models.WeeklyGoal
+		if err := query.Order("week_start_date").Limit(archiveBatchSize).Find(&goals).Error; err != nil {
+			return err
+		}
+		if len(goals) == 0 {
+			return nil
+		}
+
+		ids := make([]uuid.UUID, 0, len(goals))
+		archives := make([]models.WeeklyGoalArchive, 0, len(goals))
+		now := time.Now()
+		for _, goal := range goals {
+			ids = append(ids, goal.ID)
+			archives = append(archives, models.NewWeeklyGoalArchive(goal, now))
+		}
+
+		if archive {
+			// Re-archiving the same goal (e.g. after a failed delete) keeps the first copy
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&archives).Error; err != nil {
+				return err
+			}
+		}
+
+		result := tx.Where("id IN ?", ids).Delete(&models.WeeklyGoal{})
+		removed = result.RowsAffected
+		return result.Error
+	})
+	return removed, err
+}
+
+func (r *goalRepository) ListArchivedSince(ctx context.Context, userID uuid.UUID, since time.Time) ([]models.WeeklyGoalArchive, error) {
+	var archives []models.WeeklyGoalArchive
+	err := r.db.WithContext(ctx).Where("user_id = ? AND week_start_date >= ?", userID, since).Find(&archives).Error
+	return archives, err
+}
+
+func (r *goalRepository) ListArchived(ctx context.Context, userID uuid.UUID, offset, limit int) ([]models.WeeklyGoalArchive, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.WeeklyGoalArchive{}).Where("user_id = ?", userID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var archives []models.WeeklyGoalArchive
+	err := query.Order("week_start_date DESC").Offset(offset).Limit(limit).Find(&archives).Error
+	return archives, total, err
+}
+
+func (r *goalRepository) ListAllArchived(ctx context.Context, userID uuid.UUID) ([]models.WeeklyGoalArchive, error) {
+	var archives []models.WeeklyGoalArchive
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("week_start_date").Find(&archives).Error
+	return archives, err
+}
+
+func (r *goalRepository) CountArchivedByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.WeeklyGoalArchive{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
 func (r *goalRepository) CreateGoalDefinition(ctx context.Context, def *models.GoalDefinition) error {
 	return r.db.WithContext(ctx).Create(def).Error
 }
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrJobLockLost is returned when a worker reports the outcome of a job it no longer holds,
+// e.g. because RequeueStale released it and another worker claimed it
+var ErrJobLockLost = errors.New("job is no longer locked by this worker")
+
+type JobRepository interface {
+	Enqueue(ctx context.Context, job *models.Job) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Job, error)
+	// FindActive returns the user's queued or running job of the given type, if any
+	FindActive(ctx context.Context, jobType string, userID uuid.UUID) (*models.Job, error)
+	// Claim locks the next runnable job with FOR UPDATE SKIP LOCKED and marks it running,
+	// so concurrent workers (and server instances) never pick the same job
+	Claim(ctx context.Context, workerID string, now time.Time) (*models.Job, error)
+	// Complete, Retry and Bury only change a job that is running under workerID, and return
+	// ErrJobLockLost otherwise
+	Complete(ctx context.Context, id uuid.UUID, workerID string, result datatypes.JSON) error
+	// Retry puts a failed job back in the queue to run again at runAt
+	Retry(ctx context.Context, id uuid.UUID, workerID, errMsg string, runAt time.Time) error
+	// Bury moves a job to the dead-letter state
+	Bury(ctx context.Context, id uuid.UUID, workerID, errMsg string) error
+	// RequeueStale releases running jobs locked before the cutoff, e.g. by a crashed worker
+	RequeueStale(ctx context.Context, lockedBefore time.Time) (int64, error)
+	DeleteFinishedBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type jobRepository struct {
+	db *gorm.DB
+}
+
+func NewJobRepository(db *gorm.DB) JobRepository {
+	return &jobRepository{db: db}
+}
+
+func (r *jobRepository) Enqueue(ctx context.Context, job *models.Job) error {
+	job.Status = models.JobStatusQueued
+	if job.RunAt.IsZero() {
+		job.RunAt = time.Now()
+	}
+	return r.db.WithContext(ctx).Create(job).Error
+}
+
+func (r *jobRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Job, error) {
+	var job models.Job
+	err := r.db.WithContext(ctx).First(&job, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &job, err
+}
+
+func (r *jobRepository) FindActive(ctx context.Context, jobType string, userID uuid.UUID) (*models.Job, error) {
+	var job models.Job
+	err := r.db.WithContext(ctx).
+		Where("type = ? AND user_id = ? AND status IN ?", jobType, userID, []string{models.JobStatusQueued, models.JobStatusRunning}).
+		Order("created_at").
+		First(&job).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &job, err
+}
+
+func (r *jobRepository) Claim(ctx context.Context, workerID string, now time.Time) (*models.Job, error) {
+	var job models.Job
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND run_at <= ?", models.JobStatusQueued, now).
+			Order("run_at").
+			First(&job).Error
+		if err != nil {
+			return err
+		}
+
+		job.Status = models.JobStatusRunning
+		job.Attempts++
+		job.LockedBy = workerID
+		job.LockedAt = &now
+		return tx.Model(&job).Updates(map[string]interface{}{
+			"status":    job.Status,
+			"attempts":  job.Attempts,
+			"locked_by": job.LockedBy,
+			"locked_at": job.LockedAt,
+		}).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *jobRepository) Complete(ctx context.Context, id uuid.UUID, workerID string, result datatypes.JSON) error {
+	return r.finish(ctx, id, workerID, map[string]interface{}{
+		"status":      models.JobStatusSucceeded,
+		"result":      result,
+		"error":       "",
+		"locked_by":   "",
+		"locked_at":   nil,
+		"finished_at": time.Now(),
+	})
+}
+
+func (r *jobRepository) Retry(ctx context.Context, id uuid.UUID, workerID, errMsg string, runAt time.Time) error {
+	return r.finish(ctx, id, workerID, map[string]interface{}{
+		"status":    models.JobStatusQueued,
+		"error":     errMsg,
+		"run_at":    runAt,
+		"locked_by": "",
+		"locked_at": nil,
+	})
+}
+
+func (r *jobRepository) Bury(ctx context.Context, id uuid.UUID, workerID, errMsg string) error {
+	return r.finish(ctx, id, workerID, map[string]interface{}{
+		"status":      models.JobStatusDead,
+		"error":       errMsg,
+		"locked_by":   "",
+		"locked_at":   nil,
+		"finished_at": time.Now(),
+	})
+}
+
+// finish applies a job's outcome if the job is still running under workerID
+func (r *jobRepository) finish(ctx context.Context, id uuid.UUID, workerID string, updates map[string]interface{}) error {
+	result := r.db.WithContext(ctx).Model(&models.Job{}).
+		Where("id = ? AND status = ? AND locked_by = ?", id, models.JobStatusRunning, workerID).
+		Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrJobLockLost
+	}
+	return nil
+}
+
+func (r *jobRepository) RequeueStale(ctx context.Context, lockedBefore time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&models.Job{}).
+		Where("status = ? AND locked_at < ?", models.JobStatusRunning, lockedBefore).
+		Updates(map[string]interface{}{
+			"status":    models.JobStatusQueued,
+			"run_at":    time.Now(),
+			"locked_by": "",
+			"locked_at": nil,
+		})
+	return result.RowsAffected, result.Error
+}
+
+func (r *jobRepository) DeleteFinishedBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("status IN ? AND finished_at < ?", []string{models.JobStatusSucceeded, models.JobStatusDead}, before).
+		Delete(&models.Job{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// LoginAttemptStore keeps failed login counters. Use the Postgres implementation when running
+// more than one server instance, the in-memory one for tests and single-instance setups.
+type LoginAttemptStore interface {
+	// Get returns the counter for key, or nil if there is none
+	Get(ctx context.Context, key string) (*models.LoginAttempt, error)
+	// Increment records a failure, restarting the count if the previous failure is older than window
+	Increment(ctx context.Context, key string, now time.Time, window time.Duration) (*models.LoginAttempt, error)
+	LockUntil(ctx context.Context, key string, until time.Time) error
+	Reset(ctx context.Context, key string) error
+}
+
+type postgresLoginAttemptStore struct {
+	db *gorm.DB
+}
+
+func NewPostgresLoginAttemptStore(db *gorm.DB) LoginAttemptStore {
+	return &postgresLoginAttemptStore{db: db}
+}
+
+func (s *postgresLoginAttemptStore) Get(ctx context.Context, key string) (*models.LoginAttempt, error) {
+	var attempt models.LoginAttempt
+	err := s.db.WithContext(ctx).First(&attempt, "key = ?", key).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &attempt, err
+}
+
+func (s *postgresLoginAttemptStore) Increment(ctx context.Context, key string, now time.Time, window time.Duration) (*models.LoginAttempt, error) {
+	var attempt models.LoginAttempt
+	err := s.db.WithContext(ctx).Raw(`
+		INSERT INTO login_attempts (key, failures, last_failure_at)
+		VALUES (?, 1, ?)
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE WHEN login_attempts.last_failure_at < ? THEN 1 ELSE login_attempts.failures + 1 END,
+			last_failure_at = EXCLUDED.last_failure_at
+		RETURNING key, failures, last_failure_at, locked_until
+	`, key, now, now.Add(-window)).Scan(&attempt).Error
+	if err != nil {
+		return nil, err
+	}
+	return &attempt, nil
+}
+
+func (s *postgresLoginAttemptStore) LockUntil(ctx context.Context, key string, until time.Time) error {
+	return s.db.WithContext(ctx).Model(&models.LoginAttempt{}).Where("key = ?", key).Update("locked_until", until).Error
+}
+
+func (s *postgresLoginAttemptStore) Reset(ctx context.Context, key string) error {
+	return s.db.WithContext(ctx).Where("key = ?", key).Delete(&models.LoginAttempt{}).Error
+}
+
+type memoryLoginAttemptStore struct {
+	mu       sync.Mutex
+	attempts map[string]models.LoginAttempt
+}
+
+func NewMemoryLoginAttemptStore() LoginAttemptStore {
+	return &memoryLoginAttemptStore{attempts: make(map[string]models.LoginAttempt)}
+}
+
+func (s *memoryLoginAttemptStore) Get(ctx context.Context, key string) (*models.LoginAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	attempt, ok := s.attempts[key]
+	if !ok {
+		return nil, nil
+	}
+	return &attempt, nil
+}
+
+func (s *memoryLoginAttemptStore) Increment(ctx context.Context, key string, now time.Time, window time.Duration) (*models.LoginAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	attempt, ok := s.attempts[key]
+	if !ok || attempt.LastFailureAt.Before(now.Add(-window)) {
+		attempt = models.LoginAttempt{Key: key, LockedUntil: attempt.LockedUntil}
+	}
+	attempt.Failures++
+	attempt.LastFailureAt = now
+	s.attempts[key] = attempt
+	return &attempt, nil
+}
+
+func (s *memoryLoginAttemptStore) LockUntil(ctx context.Context, key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if attempt, ok := s.attempts[key]; ok {
+		attempt.LockedUntil = &until
+		s.attempts[key] = attempt
+	}
+	return nil
+}
+
+func (s *memoryLoginAttemptStore) Reset(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.attempts, key)
+	return nil
+}
+
+type FailedLoginRepository interface {
+	Create(ctx context.Context, entry *models.FailedLogin) error
+	ListByUser(ctx context.Context, userID uuid.UUID, limit int) ([]models.FailedLogin, error)
+}
+
+type failedLoginRepository struct {
+	db *gorm.DB
+}
+
+func NewFailedLoginRepository(db *gorm.DB) FailedLoginRepository {
+	return &failedLoginRepository{db: db}
+}
+
+func (r *failedLoginRepository) Create(ctx context.Context, entry *models.FailedLogin) error {
+	return r.db.WithContext(ctx).Create(entry).Error
+}
+
+func (r *failedLoginRepository) ListByUser(ctx context.Context, userID uuid.UUID, limit int) ([]models.FailedLogin, error) {
+	var entries []models.FailedLogin
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").Limit(limit).Find(&entries).Error
+	return entries, err
+}
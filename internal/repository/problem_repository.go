@@ -3,6 +3,8 @@ package repository
 import (
 	"context"
 	"math/rand"
+	"sync"
+	"time"
 
 	"github.com/devlpr-nitish/leetcode-tracker-backend/pkg/leetcode"
 )
@@ -10,14 +12,33 @@ import (
 type ProblemRepository interface {
 	GetProblemsByTopic(ctx context.Context, topic string, limit int) ([]leetcode.APIQuestion, error)
 	GetProblemsByDifficulty(ctx context.Context, difficulty string, limit int) ([]leetcode.APIQuestion, error)
+	// GetProblemsPage fetches one page of the problem list. Empty difficulty or topics match everything.
+	GetProblemsPage(ctx context.Context, difficulty string, topics []string, limit, skip int) ([]leetcode.APIQuestion, error)
+	// CountByTopic returns the catalog size of a topic tag, or of the whole catalog for an empty topic.
+	// Counts are cached for the repository's TTL, and failures for a minute.
+	CountByTopic(ctx context.Context, topic string) (int, error)
 }
 
 type problemRepository struct {
 	client *leetcode.Client
+
+	countTTL time.Duration
+	mu       sync.Mutex
+	counts   map[string]cachedCount
 }
 
-func NewProblemRepository(client *leetcode.Client) ProblemRepository {
-	return &problemRepository{client: client}
+type cachedCount struct {
+	count     int
+	err       error
+	fetchedAt time.Time
+}
+
+// countFailureTTL is how long a failed count is remembered, so an upstream outage
+// does not turn every request into a new round of upstream calls
+const countFailureTTL = time.Minute
+
+func NewProblemRepository(client *leetcode.Client, countTTL time.Duration) ProblemRepository {
+	return &problemRepository{client: client, countTTL: countTTL, counts: make(map[string]cachedCount)}
 }
 
 func (r *problemRepository) GetProblemsByTopic(ctx context.Context, topic string, limit int) ([]leetcode.APIQuestion, error) {
@@ -31,3 +52,33 @@ func (r *problemRepository) GetProblemsByDifficulty(ctx context.Context, difficu
 	skip := rand.Intn(100)
 	return r.client.GetProblems(limit, skip, nil, difficulty)
 }
+
+func (r *problemRepository) GetProblemsPage(ctx context.Context, difficulty string, topics []string, limit, skip int) ([]leetcode.APIQuestion, error) {
+	return r.client.GetProblems(limit, skip, topics, difficulty)
+}
+
+func (r *problemRepository) CountByTopic(ctx context.Context, topic string) (int, error) {
+	r.mu.Lock()
+	cached, ok := r.counts[topic]
+	r.mu.Unlock()
+	if ok {
+		ttl := r.countTTL
+		if cached.err != nil {
+			ttl = countFailureTTL
+		}
+		if time.Since(cached.fetchedAt) < ttl {
+			return cached.count, cached.err
+		}
+	}
+
+	var tags []string
+	if topic != "" {
+		tags = []string{topic}
+	}
+	count, err := r.client.GetProblemCount(tags, "")
+
+	r.mu.Lock()
+	r.counts[topic] = cachedCount{count: count, err: err, fetchedAt: time.Now()}
+	r.mu.Unlock()
+	return count, err
+}
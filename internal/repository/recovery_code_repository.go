@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type RecoveryCodeRepository interface {
+	// ReplaceForUser deletes the user's existing codes and stores the new set
+	ReplaceForUser(ctx context.Context, userID uuid.UUID, codes []models.RecoveryCode) error
+	// Consume marks an unused code as used. It reports false if no such unused code exists.
+	Consume(ctx context.Context, userID uuid.UUID, codeHash string, at time.Time) (bool, error)
+	DeleteForUser(ctx context.Context, userID uuid.UUID) error
+}
+
+type recoveryCodeRepository struct {
+	db *gorm.DB
+}
+
+func NewRecoveryCodeRepository(db *gorm.DB) RecoveryCodeRepository {
+	return &recoveryCodeRepository{db: db}
+}
+
+func (r *recoveryCodeRepository) ReplaceForUser(ctx context.Context, userID uuid.UUID, codes []models.RecoveryCode) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+func (r *recoveryCodeRepository) Consume(ctx context.Context, userID uuid.UUID, codeHash string, at time.Time) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", at)
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *recoveryCodeRepository) DeleteForUser(ctx context.Context, userID uuid.UUID) error {
+	return r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error
+}
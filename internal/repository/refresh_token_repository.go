@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token *models.RefreshToken) error
+	GetByHash(ctx context.Context, hash string) (*models.RefreshToken, error)
+	// Consume marks an unused token as rotated. It reports false if the token was already used or revoked.
+	Consume(ctx context.Context, id uuid.UUID, at time.Time) (bool, error)
+	RevokeFamily(ctx context.Context, familyID uuid.UUID, at time.Time) error
+	RevokeAllForUser(ctx context.Context, userID uuid.UUID, at time.Time) error
+	// RevokeOtherFamilies revokes every session of the user except keepFamilyID
+	RevokeOtherFamilies(ctx context.Context, userID, keepFamilyID uuid.UUID, at time.Time) error
+	IsFamilyActive(ctx context.Context, familyID uuid.UUID, now time.Time) (bool, error)
+	ListActive(ctx context.Context, userID uuid.UUID, now time.Time) ([]models.RefreshToken, error)
+}
+
+type refreshTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewRefreshTokenRepository(db *gorm.DB) RefreshTokenRepository {
+	return &refreshTokenRepository{db: db}
+}
+
+func (r *refreshTokenRepository) Create(ctx context.Context, token *models.RefreshToken) error {
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+func (r *refreshTokenRepository) GetByHash(ctx context.Context, hash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := r.db.WithContext(ctx).First(&token, "token_hash = ?", hash).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &token, err
+}
+
+func (r *refreshTokenRepository) Consume(ctx context.Context, id uuid.UUID, at time.Time) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&models.RefreshToken{}).
+		Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", id).
+		Update("used_at", at)
+	return res.RowsAffected == 1, res.Error
+}
+
+func (r *refreshTokenRepository) RevokeFamily(ctx context.Context, familyID uuid.UUID, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", at).Error
+}
+
+func (r *refreshTokenRepository) RevokeAllForUser(ctx context.Context, userID uuid.UUID, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", at).Error
+}
+
+func (r *refreshTokenRepository) RevokeOtherFamilies(ctx context.Context, userID, keepFamilyID uuid.UUID, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.RefreshToken{}).
+		Where("user_id = ? AND family_id <> ? AND revoked_at IS NULL", userID, keepFamilyID).
+		Update("revoked_at", at).Error
+}
+
+func (r *refreshTokenRepository) IsFamilyActive(ctx context.Context, familyID uuid.UUID, now time.Time) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.RefreshToken{}).
+		Where("family_id = ? AND used_at IS NULL AND revoked_at IS NULL AND expires_at > ?", familyID, now).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// ListActive returns the current (unrotated) token of every live session for the user
+func (r *refreshTokenRepository) ListActive(ctx context.Context, userID uuid.UUID, now time.Time) ([]models.RefreshToken, error) {
+	var tokens []models.RefreshToken
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND used_at IS NULL AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Order("created_at DESC").
+		Find(&tokens).Error
+	return tokens, err
+}
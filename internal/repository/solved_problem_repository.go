@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SolvedProblemRepository interface {
+	// Upsert records problems, widening FirstSolvedAt/LastSolvedAt of ones already known.
+	// Each title slug must appear at most once in problems.
+	Upsert(ctx context.Context, problems []models.UserSolvedProblem) error
+	// ListByUser returns the user's solved problems, most recently solved first
+	ListByUser(ctx context.Context, userID uuid.UUID, offset, limit int) ([]models.UserSolvedProblem, int64, error)
+	// ListAllByUser returns every solved problem of the user, only with slug and title loaded
+	ListAllByUser(ctx context.Context, userID uuid.UUID) ([]models.UserSolvedProblem, error)
+	// ListFullByUser returns every solved problem of the user with all fields, oldest first
+	ListFullByUser(ctx context.Context, userID uuid.UUID) ([]models.UserSolvedProblem, error)
+	CountByUser(ctx context.Context, userID uuid.UUID) (int64, error)
+}
+
+type solvedProblemRepository struct {
+	db *gorm.DB
+}
+
+func NewSolvedProblemRepository(db *gorm.DB) SolvedProblemRepository {
+	return &solvedProblemRepository{db: db}
+}
+
+func (r *solvedProblemRepository) Upsert(ctx context.Context, problems []models.UserSolvedProblem) error {
+	if len(problems) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "user_id"}, {Name: "title_slug"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"title":           gorm.Expr("EXCLUDED.title"),
+				"language":        gorm.Expr("CASE WHEN EXCLUDED.last_solved_at >= user_solved_problems.last_solved_at THEN EXCLUDED.language ELSE user_solved_problems.language END"),
+				"first_solved_at": gorm.Expr("LEAST(user_solved_problems.first_solved_at, EXCLUDED.first_solved_at)"),
+				"last_solved_at":  gorm.Expr("GREATEST(user_solved_problems.last_solved_at, EXCLUDED.last_solved_at)"),
+				"updated_at":      gorm.Expr("EXCLUDED.updated_at"),
+			}),
+		}).
+		Create(&problems).Error
+}
+
+func (r *solvedProblemRepository) ListByUser(ctx context.Context, userID uuid.UUID, offset, limit int) ([]models.UserSolvedProblem, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.UserSolvedProblem{}).Where("user_id = ?", userID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var problems []models.UserSolvedProblem
+	err := query.Order("last_solved_at DESC").Offset(offset).Limit(limit).Find(&problems).Error
+	return problems, total, err
+}
+
+func (r *solvedProblemRepository) ListAllByUser(ctx context.Context, userID uuid.UUID) ([]models.UserSolvedProblem, error) {
+	var problems []models.UserSolvedProblem
+	err := r.db.WithContext(ctx).Select("title_slug", "title").Where("user_id = ?", userID).Find(&problems).Error
+	return problems, err
+}
+
+func (r *solvedProblemRepository) ListFullByUser(ctx context.Context, userID uuid.UUID) ([]models.UserSolvedProblem, error) {
+	var problems []models.UserSolvedProblem
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("first_solved_at").Find(&problems).Error
+	return problems, err
+}
+
+func (r *solvedProblemRepository) CountByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.UserSolvedProblem{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type StatSnapshotRepository interface {
+	Create(ctx context.Context, snapshot *models.UserStatSnapshot) error
+	// ListByUser returns the user's snapshots taken within [from, to], oldest first
+	ListByUser(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]models.UserStatSnapshot, error)
+}
+
+type statSnapshotRepository struct {
+	db *gorm.DB
+}
+
+func NewStatSnapshotRepository(db *gorm.DB) StatSnapshotRepository {
+	return &statSnapshotRepository{db: db}
+}
+
+func (r *statSnapshotRepository) Create(ctx context.Context, snapshot *models.UserStatSnapshot) error {
+	return r.db.WithContext(ctx).Create(snapshot).Error
+}
+
+func (r *statSnapshotRepository) ListByUser(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]models.UserStatSnapshot, error) {
+	var snapshots []models.UserStatSnapshot
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND created_at BETWEEN ? AND ?", userID, from, to).
+		Order("created_at").
+		Find(&snapshots).Error
+	return snapshots, err
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type SyncEventRepository interface {
+	Create(ctx context.Context, event *models.SyncEvent) error
+	// ListByUser returns the user's events created after since, newest first
+	ListByUser(ctx context.Context, userID uuid.UUID, since time.Time, limit int) ([]models.SyncEvent, error)
+	// ListAllByUser returns every event of the user, oldest first
+	ListAllByUser(ctx context.Context, userID uuid.UUID) ([]models.SyncEvent, error)
+	CountByUser(ctx context.Context, userID uuid.UUID) (int64, error)
+}
+
+type syncEventRepository struct {
+	db *gorm.DB
+}
+
+func NewSyncEventRepository(db *gorm.DB) SyncEventRepository {
+	return &syncEventRepository{db: db}
+}
+
+func (r *syncEventRepository) Create(ctx context.Context, event *models.SyncEvent) error {
+	return r.db.WithContext(ctx).Create(event).Error
+}
+
+func (r *syncEventRepository) ListByUser(ctx context.Context, userID uuid.UUID, since time.Time, limit int) ([]models.SyncEvent, error) {
+	var events []models.SyncEvent
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND created_at > ?", userID, since).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&events).Error
+	return events, err
+}
+
+func (r *syncEventRepository) ListAllByUser(ctx context.Context, userID uuid.UUID) ([]models.SyncEvent, error) {
+	var events []models.SyncEvent
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at").Find(&events).Error
+	return events, err
+}
+
+func (r *syncEventRepository) CountByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.SyncEvent{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
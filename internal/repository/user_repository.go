@@ -3,6 +3,9 @@ package repository
 import (
 	"context"
 	"errors"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/models"
 	"github.com/google/uuid"
@@ -15,6 +18,33 @@ type UserRepository interface {
 	GetByUsername(ctx context.Context, username string) (*models.User, error)
 	GetByEmail(ctx context.Context, email string) (*models.User, error)
 	Update(ctx context.Context, user *models.User) error
+	// UpdateColumns writes only the given columns, leaving changes made since the user was loaded alone
+	UpdateColumns(ctx context.Context, id uuid.UUID, columns map[string]interface{}) error
+	Search(ctx context.Context, filter UserFilter) ([]models.User, int64, error)
+	// GetByIDUnscoped also returns soft-deleted users
+	GetByIDUnscoped(ctx context.Context, id uuid.UUID) (*models.User, error)
+	SoftDelete(ctx context.Context, id uuid.UUID) error
+	Restore(ctx context.Context, id uuid.UUID) error
+	// GetDeletedByIdentifier finds a soft-deleted user by username or email
+	GetDeletedByIdentifier(ctx context.Context, identifier string) (*models.User, error)
+	ListScheduledForPurge(ctx context.Context, before time.Time) ([]models.User, error)
+	// ClaimDueForSync atomically moves next_sync_at of up to limit verified users whose sync is
+	// due, and who were not synced after syncedBefore, to claimUntil and returns them, least
+	// recently synced first. Rows locked by another claim are skipped, so concurrent schedulers
+	// never pick the same user.
+	ClaimDueForSync(ctx context.Context, now, syncedBefore, claimUntil time.Time, limit int) ([]models.User, error)
+	SetNextSyncAt(ctx context.Context, id uuid.UUID, next time.Time) error
+	// Purge permanently deletes a user together with their goals, activity, comparisons and credentials
+	Purge(ctx context.Context, user *models.User) error
+}
+
+// UserFilter narrows down user listings for the admin API
+type UserFilter struct {
+	Query          string // Matches username, email or name
+	Role           string
+	IncludeDeleted bool
+	Offset         int
+	Limit          int
 }
 
 type userRepository struct {
@@ -56,7 +86,144 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*models.
 	return &user, err
 }
 
-
 func (r *userRepository) Update(ctx context.Context, user *models.User) error {
 	return r.db.WithContext(ctx).Save(user).Error
 }
+
+func (r *userRepository) UpdateColumns(ctx context.Context, id uuid.UUID, columns map[string]interface{}) error {
+	return r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", id).Updates(columns).Error
+}
+
+func (r *userRepository) Search(ctx context.Context, filter UserFilter) ([]models.User, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.User{})
+	if filter.IncludeDeleted {
+		query = query.Unscoped()
+	}
+	if filter.Query != "" {
+		like := "%" + escapeLike(strings.ToLower(filter.Query)) + "%"
+		query = query.Where(`LOWER(username) LIKE ? ESCAPE '\' OR LOWER(email) LIKE ? ESCAPE '\' OR LOWER(name) LIKE ? ESCAPE '\'`, like, like, like)
+	}
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []models.User
+	err := query.Order("created_at DESC").Offset(filter.Offset).Limit(filter.Limit).Find(&users).Error
+	return users, total, err
+}
+
+func (r *userRepository) GetByIDUnscoped(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	var user models.User
+	err := r.db.WithContext(ctx).Unscoped().First(&user, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &user, err
+}
+
+func (r *userRepository) SoftDelete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&models.User{}, "id = ?", id).Error
+}
+
+func (r *userRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Unscoped().Model(&models.User{}).Where("id = ?", id).Update("deleted_at", nil).Error
+}
+
+func (r *userRepository) GetDeletedByIdentifier(ctx context.Context, identifier string) (*models.User, error) {
+	var user models.User
+	err := r.db.WithContext(ctx).Unscoped().
+		Where("deleted_at IS NOT NULL AND (username = ? OR email = ?)", identifier, identifier).
+		First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &user, err
+}
+
+func (r *userRepository) ListScheduledForPurge(ctx context.Context, before time.Time) ([]models.User, error) {
+	var users []models.User
+	err := r.db.WithContext(ctx).Unscoped().
+		Where("deleted_at IS NOT NULL AND deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= ?", before).
+		Find(&users).Error
+	return users, err
+}
+
+func (r *userRepository) ClaimDueForSync(ctx context.Context, now, syncedBefore, claimUntil time.Time, limit int) ([]models.User, error) {
+	var users []models.User
+	err := r.db.WithContext(ctx).Raw(`
+		UPDATE users SET next_sync_at = ?
+		WHERE id IN (
+			SELECT id FROM users
+			WHERE deleted_at IS NULL
+				AND leet_code_verified = TRUE
+				AND (next_sync_at IS NULL OR next_sync_at <= ?)
+				AND (last_synced_at IS NULL OR last_synced_at <= ?)
+			ORDER BY last_synced_at NULLS FIRST
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *
+	`, claimUntil, now, syncedBefore, limit).Scan(&users).Error
+	if err != nil {
+		return nil, err
+	}
+	// RETURNING gives no order
+	sort.SliceStable(users, func(i, j int) bool {
+		if users[i].LastSyncedAt == nil || users[j].LastSyncedAt == nil {
+			return users[i].LastSyncedAt == nil && users[j].LastSyncedAt != nil
+		}
+		return users[i].LastSyncedAt.Before(*users[j].LastSyncedAt)
+	})
+	return users, nil
+}
+
+func (r *userRepository) SetNextSyncAt(ctx context.Context, id uuid.UUID, next time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", id).UpdateColumn("next_sync_at", next).Error
+}
+
+func (r *userRepository) Purge(ctx context.Context, user *models.User) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		owned := []interface{}{
+			&models.WeeklyGoal{},
+			&models.WeeklyGoalArchive{},
+			&models.UserGoalPreferences{},
+			&models.ActivityLog{},
+			&models.RefreshToken{},
+			&models.UserToken{},
+			&models.RecoveryCode{},
+			&models.APIKey{},
+			&models.DataExport{},
+			&models.UserStatSnapshot{},
+			&models.FailedLogin{},
+			&models.Job{},
+			&models.SyncEvent{},
+			&models.UserDailyActivity{},
+			&models.UserSolvedProblem{},
+		}
+		for _, model := range owned {
+			if err := tx.Unscoped().Where("user_id = ?", user.ID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Unscoped().
+			Where("user1_name = ? OR user2_name = ?", user.Username, user.Username).
+			Delete(&models.UserComparison{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Unscoped().Delete(&models.User{}, "id = ?", user.ID).Error
+	})
+}
+
+// likeEscaper makes LIKE wildcards in user input match literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
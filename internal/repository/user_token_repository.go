@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type UserTokenRepository interface {
+	Create(ctx context.Context, token *models.UserToken) error
+	GetByHash(ctx context.Context, purpose, hash string) (*models.UserToken, error)
+	// Consume marks an unused token as used. It reports false if the token was already used.
+	Consume(ctx context.Context, id uuid.UUID, at time.Time) (bool, error)
+	// InvalidateForUser marks every outstanding token of the given purpose as used
+	InvalidateForUser(ctx context.Context, userID uuid.UUID, purpose string, at time.Time) error
+}
+
+type userTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewUserTokenRepository(db *gorm.DB) UserTokenRepository {
+	return &userTokenRepository{db: db}
+}
+
+func (r *userTokenRepository) Create(ctx context.Context, token *models.UserToken) error {
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+func (r *userTokenRepository) GetByHash(ctx context.Context, purpose, hash string) (*models.UserToken, error) {
+	var token models.UserToken
+	err := r.db.WithContext(ctx).First(&token, "purpose = ? AND token_hash = ?", purpose, hash).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &token, err
+}
+
+func (r *userTokenRepository) Consume(ctx context.Context, id uuid.UUID, at time.Time) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&models.UserToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", at)
+	return res.RowsAffected == 1, res.Error
+}
+
+func (r *userTokenRepository) InvalidateForUser(ctx context.Context, userID uuid.UUID, purpose string, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", at).Error
+}
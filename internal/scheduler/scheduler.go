@@ -0,0 +1,25 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Every runs task on each tick of interval until ctx is cancelled, logging failures.
+// The first run happens after one interval, not immediately.
+func Every(ctx context.Context, interval time.Duration, name string, task func(context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := task(ctx); err != nil {
+				log.Printf("%s failed: %v", name, err)
+			}
+		}
+	}
+}
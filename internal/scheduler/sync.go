@@ -0,0 +1,98 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/config"
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/models"
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/repository"
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/services"
+)
+
+// syncBatchSize caps how many due users are picked up per poll
+const syncBatchSize = 100
+
+// SyncScheduler periodically re-syncs every verified user from LeetCode
+type SyncScheduler struct {
+	UserRepo     repository.UserRepository
+	UserService  *services.UserService
+	Interval     time.Duration
+	Jitter       time.Duration
+	Concurrency  int
+	MinAge       time.Duration
+	PollInterval time.Duration
+}
+
+func NewSyncScheduler(userRepo repository.UserRepository, userService *services.UserService, cfg *config.Config) *SyncScheduler {
+	concurrency := cfg.SyncConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &SyncScheduler{
+		UserRepo:     userRepo,
+		UserService:  userService,
+		Interval:     cfg.SyncInterval,
+		Jitter:       cfg.SyncJitter,
+		Concurrency:  concurrency,
+		MinAge:       cfg.SyncMinAge,
+		PollInterval: cfg.SyncPollInterval,
+	}
+}
+
+// Run polls for due users until ctx is cancelled
+func (s *SyncScheduler) Run(ctx context.Context) {
+	Every(ctx, s.PollInterval, "Scheduled sync", s.RunOnce)
+}
+
+// RunOnce claims one batch of due users and syncs them, at most Concurrency at a time.
+// Claiming moves their next sync to the next regular slot, so other instances polling at
+// the same time skip them, and a crash before the sync only delays them by one interval.
+func (s *SyncScheduler) RunOnce(ctx context.Context) error {
+	now := time.Now()
+	users, err := s.UserRepo.ClaimDueForSync(ctx, now, now.Add(-s.MinAge), s.nextSyncAt(now), syncBatchSize)
+	if err != nil {
+		return err
+	}
+
+	sem := make(chan struct{}, s.Concurrency)
+	var wg sync.WaitGroup
+	for i := range users {
+		if ctx.Err() != nil {
+			break
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(user *models.User) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			s.syncUser(ctx, user)
+		}(&users[i])
+	}
+	wg.Wait()
+
+	return nil
+}
+
+func (s *SyncScheduler) syncUser(ctx context.Context, user *models.User) {
+	if _, err := s.UserService.SyncUser(ctx, user.Username); err != nil {
+		log.Printf("Scheduled sync of %s failed: %v", user.Username, err)
+	}
+
+	// Failed syncs are retried on the next regular slot rather than on every poll
+	if err := s.UserRepo.SetNextSyncAt(ctx, user.ID, s.nextSyncAt(time.Now())); err != nil {
+		log.Printf("Failed to schedule next sync of %s: %v", user.Username, err)
+	}
+}
+
+func (s *SyncScheduler) nextSyncAt(from time.Time) time.Time {
+	next := from.Add(s.Interval)
+	if s.Jitter > 0 {
+		next = next.Add(time.Duration(rand.Int63n(int64(s.Jitter))))
+	}
+	return next
+}
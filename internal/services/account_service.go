@@ -0,0 +1,253 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/config"
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/models"
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const maxDisplayNameLength = 100
+
+var (
+	ErrEmailTaken         = errors.New("email already in use")
+	ErrInvalidEmail       = errors.New("invalid email address")
+	ErrInvalidDisplayName = errors.New("display name is too long")
+	ErrRestoreExpired     = errors.New("the restore period for this account has ended")
+	ErrInvalidTimezone    = errors.New("unknown timezone")
+	ErrInvalidWeekStart   = errors.New("week start day must be a day of the week")
+)
+
+// AccountService lets users manage their own account: profile, password and deletion
+type AccountService struct {
+	UserRepo         repository.UserRepository
+	RefreshTokenRepo repository.RefreshTokenRepository
+	APIKeyRepo       repository.APIKeyRepository
+	AuthService      *AuthService
+	DeletionGrace    time.Duration
+
+	purgeHooks []PurgeHook
+}
+
+// PurgeHook is called before an account is purged, e.g. to delete files stored outside the
+// database. An error keeps the account for the next purge run.
+type PurgeHook func(ctx context.Context, user *models.User) error
+
+// AddPurgeHook registers hook. It must be called before the service is used.
+func (s *AccountService) AddPurgeHook(hook PurgeHook) {
+	s.purgeHooks = append(s.purgeHooks, hook)
+}
+
+func NewAccountService(userRepo repository.UserRepository, refreshTokenRepo repository.RefreshTokenRepository, apiKeyRepo repository.APIKeyRepository, authService *AuthService, cfg *config.Config) *AccountService {
+	return &AccountService{
+		UserRepo:         userRepo,
+		RefreshTokenRepo: refreshTokenRepo,
+		APIKeyRepo:       apiKeyRepo,
+		AuthService:      authService,
+		DeletionGrace:    cfg.AccountDeletionGrace,
+	}
+}
+
+// ProfileUpdate holds the fields a user may change on their own account. Nil fields are left untouched.
+type ProfileUpdate struct {
+	Email        *string `json:"email"`
+	DisplayName  *string `json:"display_name"`
+	GoalStrategy *string `json:"goal_strategy"`
+	Timezone     *string `json:"timezone"`       // IANA name such as "Europe/Berlin"
+	WeekStartDay *string `json:"week_start_day"` // "Monday" to "Sunday"
+}
+
+// UpdateProfile applies a partial update. Changing the email resets its verification.
+func (s *AccountService) UpdateProfile(ctx context.Context, user *models.User, update ProfileUpdate) (*models.User, error) {
+	emailChanged := false
+	columns := map[string]interface{}{}
+
+	if update.Email != nil {
+		email := strings.TrimSpace(*update.Email)
+		if !strings.Contains(email, "@") {
+			return nil, ErrInvalidEmail
+		}
+		if !strings.EqualFold(email, user.Email) {
+			existing, err := s.UserRepo.GetByEmail(ctx, email)
+			if err != nil {
+				return nil, err
+			}
+			if existing != nil {
+				return nil, ErrEmailTaken
+			}
+			user.Email = email
+			user.EmailVerified = false
+			user.EmailVerifiedAt = nil
+			columns["email"] = user.Email
+			columns["email_verified"] = false
+			columns["email_verified_at"] = nil
+			emailChanged = true
+		}
+	}
+
+	if update.DisplayName != nil {
+		name := strings.TrimSpace(*update.DisplayName)
+		if len(name) > maxDisplayNameLength {
+			return nil, ErrInvalidDisplayName
+		}
+		user.DisplayName = name
+		columns["display_name"] = name
+	}
+
+	if update.GoalStrategy != nil {
+		if _, err := goalPlannerFor(*update.GoalStrategy); err != nil || *update.GoalStrategy == "" {
+			return nil, ErrUnknownStrategy
+		}
+		user.GoalStrategy = *update.GoalStrategy
+		columns["goal_strategy"] = user.GoalStrategy
+	}
+
+	// Goal weeks already planned keep the zone and start date they were planned with
+	if update.Timezone != nil {
+		name := strings.TrimSpace(*update.Timezone)
+		if _, err := time.LoadLocation(name); err != nil || name == "" || name == "Local" {
+			return nil, ErrInvalidTimezone
+		}
+		user.Timezone = name
+		columns["timezone"] = name
+	}
+
+	if update.WeekStartDay != nil {
+		day := canonicalDay(*update.WeekStartDay)
+		if day == "" {
+			return nil, ErrInvalidWeekStart
+		}
+		user.WeekStartDay = day
+		columns["week_start_day"] = day
+	}
+
+	if len(columns) > 0 {
+		if err := s.UserRepo.UpdateColumns(ctx, user.ID, columns); err != nil {
+			return nil, err
+		}
+	}
+
+	if emailChanged {
+		s.AuthService.trySendEmailVerification(ctx, user)
+	}
+	return user, nil
+}
+
+// ChangePassword requires the current password and signs out every other session
+func (s *AccountService) ChangePassword(ctx context.Context, principal *Principal, currentPassword, newPassword string) error {
+	user := principal.User
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(currentPassword)); err != nil {
+		return ErrInvalidCredentials
+	}
+	if err := validatePassword(newPassword); err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	user.PasswordHash = string(hashedPassword)
+	if err := s.UserRepo.UpdateColumns(ctx, user.ID, map[string]interface{}{"password_hash": user.PasswordHash}); err != nil {
+		return err
+	}
+
+	return s.RefreshTokenRepo.RevokeOtherFamilies(ctx, user.ID, principal.SessionID, time.Now())
+}
+
+// DeleteAccount soft-deletes the account and schedules its data for purging after the grace period
+func (s *AccountService) DeleteAccount(ctx context.Context, user *models.User, password string) (*models.User, error) {
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	now := time.Now()
+	scheduledAt := now.Add(s.DeletionGrace)
+	user.DeletionScheduledAt = &scheduledAt
+	if err := s.UserRepo.UpdateColumns(ctx, user.ID, map[string]interface{}{"deletion_scheduled_at": scheduledAt}); err != nil {
+		return nil, err
+	}
+
+	if err := s.RefreshTokenRepo.RevokeAllForUser(ctx, user.ID, now); err != nil {
+		return nil, err
+	}
+	if err := s.APIKeyRepo.RevokeAllForUser(ctx, user.ID, now); err != nil {
+		return nil, err
+	}
+	if err := s.UserRepo.SoftDelete(ctx, user.ID); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// RestoreAccount undoes a self-service deletion while the grace period is still running.
+// Attempts are throttled and audited by the login guard like logins are.
+func (s *AccountService) RestoreAccount(ctx context.Context, identifier, password string, client ClientInfo) (*models.User, error) {
+	user, err := s.UserRepo.GetDeletedByIdentifier(ctx, identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	attempt := LoginAttempt{Identifier: identifier, User: user, Client: client}
+	if err := s.AuthService.Guard.Check(ctx, attempt); err != nil {
+		return nil, err
+	}
+	if user == nil {
+		s.AuthService.recordLoginFailure(ctx, attempt, FailureUnknownUser)
+		return nil, ErrInvalidCredentials
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		s.AuthService.recordLoginFailure(ctx, attempt, FailureBadPassword)
+		return nil, ErrInvalidCredentials
+	}
+	s.AuthService.recordLoginSuccess(ctx, attempt)
+	if user.DeletionScheduledAt == nil || time.Now().After(*user.DeletionScheduledAt) {
+		return nil, ErrRestoreExpired
+	}
+
+	if err := s.UserRepo.Restore(ctx, user.ID); err != nil {
+		return nil, err
+	}
+	user.DeletedAt.Valid = false
+	user.DeletionScheduledAt = nil
+	if err := s.UserRepo.UpdateColumns(ctx, user.ID, map[string]interface{}{"deletion_scheduled_at": nil}); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// PurgeDeletedAccounts permanently removes accounts whose grace period has ended
+func (s *AccountService) PurgeDeletedAccounts(ctx context.Context) error {
+	users, err := s.UserRepo.ListScheduledForPurge(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+
+	for i := range users {
+		if err := s.runPurgeHooks(ctx, &users[i]); err != nil {
+			log.Printf("Failed to purge account %s: %v", users[i].ID, err)
+			continue
+		}
+		if err := s.UserRepo.Purge(ctx, &users[i]); err != nil {
+			log.Printf("Failed to purge account %s: %v", users[i].ID, err)
+			continue
+		}
+		log.Printf("Purged account %s", users[i].ID)
+	}
+	return nil
+}
+
+func (s *AccountService) runPurgeHooks(ctx context.Context, user *models.User) error {
+	for _, hook := range s.purgeHooks {
+		if err := hook(ctx, user); err != nil {
+			return err
+		}
+	}
+	return nil
+}
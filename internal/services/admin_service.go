@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/models"
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/repository"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultAdminPageSize = 20
+	maxAdminPageSize     = 100
+)
+
+var (
+	ErrInvalidRole      = errors.New("role must be 'user' or 'admin'")
+	ErrUserNotDeleted   = errors.New("user is not deleted")
+	ErrCannotDeleteSelf = errors.New("admins cannot delete or demote themselves")
+	// ErrAdminUserNotFound is returned when the user an admin acts on does not exist
+	ErrAdminUserNotFound = errors.New("user not found")
+)
+
+// AdminService backs the /admin API used to manage users and cached data
+type AdminService struct {
+	UserRepo         repository.UserRepository
+	RefreshTokenRepo repository.RefreshTokenRepository
+	APIKeyRepo       repository.APIKeyRepository
+	ComparisonRepo   *repository.ComparisonRepository
+	UserService      *UserService
+	GoalService      *GoalService
+}
+
+func NewAdminService(userRepo repository.UserRepository, refreshTokenRepo repository.RefreshTokenRepository, apiKeyRepo repository.APIKeyRepository, comparisonRepo *repository.ComparisonRepository, userService *UserService, goalService *GoalService) *AdminService {
+	return &AdminService{
+		UserRepo:         userRepo,
+		RefreshTokenRepo: refreshTokenRepo,
+		APIKeyRepo:       apiKeyRepo,
+		ComparisonRepo:   comparisonRepo,
+		UserService:      userService,
+		GoalService:      goalService,
+	}
+}
+
+// UserPage is one page of an admin user listing
+type UserPage struct {
+	Users []models.User `json:"users"`
+	Total int64         `json:"total"`
+	Page  int           `json:"page"`
+	Limit int           `json:"limit"`
+}
+
+// ListUsers searches users, including soft-deleted ones when requested. Pages start at 1.
+func (s *AdminService) ListUsers(ctx context.Context, query, role string, includeDeleted bool, page, limit int) (*UserPage, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = defaultAdminPageSize
+	}
+	if limit > maxAdminPageSize {
+		limit = maxAdminPageSize
+	}
+
+	users, total, err := s.UserRepo.Search(ctx, repository.UserFilter{
+		Query:          query,
+		Role:           role,
+		IncludeDeleted: includeDeleted,
+		Offset:         (page - 1) * limit,
+		Limit:          limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &UserPage{Users: users, Total: total, Page: page, Limit: limit}, nil
+}
+
+// ForceSync syncs any registered user, bypassing ownership checks
+func (s *AdminService) ForceSync(ctx context.Context, username string) (*SyncResult, error) {
+	return s.UserService.SyncUser(ctx, username)
+}
+
+// RegenerateGoals generates goals for any user, replacing the week's goal when opts.Regenerate is set
+func (s *AdminService) RegenerateGoals(ctx context.Context, username string, opts GenerationOptions) (*GenerationResult, error) {
+	user, err := s.UserRepo.GetByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrAdminUserNotFound
+	}
+	return s.GoalService.GenerateWeeklyGoals(ctx, user.ID, opts)
+}
+
+// PurgeComparisons removes cached AI comparisons older than olderThan, optionally only for one username
+func (s *AdminService) PurgeComparisons(username string, olderThan time.Duration) (int64, error) {
+	return s.ComparisonRepo.PurgeComparisons(username, time.Now().Add(-olderThan))
+}
+
+// SetRole promotes or demotes a user
+func (s *AdminService) SetRole(ctx context.Context, admin *models.User, userID uuid.UUID, role string) (*models.User, error) {
+	if role != models.RoleUser && role != models.RoleAdmin {
+		return nil, ErrInvalidRole
+	}
+	if admin.ID == userID && role != models.RoleAdmin {
+		return nil, ErrCannotDeleteSelf
+	}
+
+	user, err := s.UserRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrAdminUserNotFound
+	}
+
+	user.Role = role
+	if err := s.UserRepo.UpdateColumns(ctx, user.ID, map[string]interface{}{"role": role}); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// DeleteUser soft-deletes an account and revokes all of its credentials
+func (s *AdminService) DeleteUser(ctx context.Context, admin *models.User, userID uuid.UUID) error {
+	if admin.ID == userID {
+		return ErrCannotDeleteSelf
+	}
+
+	user, err := s.UserRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrAdminUserNotFound
+	}
+
+	now := time.Now()
+	if err := s.RefreshTokenRepo.RevokeAllForUser(ctx, userID, now); err != nil {
+		return err
+	}
+	if err := s.APIKeyRepo.RevokeAllForUser(ctx, userID, now); err != nil {
+		return err
+	}
+	return s.UserRepo.SoftDelete(ctx, userID)
+}
+
+// RestoreUser brings back a soft-deleted account. Revoked sessions and API keys stay revoked.
+func (s *AdminService) RestoreUser(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	user, err := s.UserRepo.GetByIDUnscoped(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrAdminUserNotFound
+	}
+	if !user.DeletedAt.Valid {
+		return nil, ErrUserNotDeleted
+	}
+
+	if err := s.UserRepo.Restore(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.UserRepo.GetByID(ctx, userID)
+}
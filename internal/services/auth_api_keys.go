@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// Scopes that can be granted to API keys. Sessions created by logging in have every scope.
+const (
+	ScopeProfileRead  = "profile:read"
+	ScopeSyncWrite    = "sync:write"
+	ScopeGoalsRead    = "goals:read"
+	ScopeGoalsWrite   = "goals:write"
+	ScopeCompareWrite = "compare:write"
+)
+
+var AllScopes = []string{ScopeProfileRead, ScopeSyncWrite, ScopeGoalsRead, ScopeGoalsWrite, ScopeCompareWrite}
+
+const (
+	apiKeyPrefix        = "lct"
+	apiKeyTouchInterval = time.Minute // Avoids a write on every request from busy bots
+	maxActiveAPIKeys    = 25
+	maxAPIKeyNameLength = 100
+	apiKeyPrefixLength  = 8
+)
+
+var (
+	ErrInvalidAPIKey    = errors.New("invalid, expired or revoked API key")
+	ErrAPIKeyNotFound   = errors.New("API key not found")
+	ErrInvalidAPIKeyReq = errors.New("name and at least one valid scope are required")
+	ErrTooManyAPIKeys   = fmt.Errorf("at most %d active API keys are allowed", maxActiveAPIKeys)
+)
+
+// CreatedAPIKey includes the plain key, which is only ever shown at creation time
+type CreatedAPIKey struct {
+	models.APIKey
+	Key string `json:"key"`
+}
+
+// HasScope reports whether the caller may perform actions guarded by scope.
+// Token (session) principals are unrestricted.
+func (p *Principal) HasScope(scope string) bool {
+	if p.APIKeyID == nil {
+		return true
+	}
+	return slices.Contains(p.Scopes, scope)
+}
+
+// CreateAPIKey issues a new key for the user. expiresIn of zero means the key never expires.
+func (s *AuthService) CreateAPIKey(ctx context.Context, user *models.User, name string, scopes []string, expiresIn time.Duration) (*CreatedAPIKey, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > maxAPIKeyNameLength || len(scopes) == 0 {
+		return nil, ErrInvalidAPIKeyReq
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(AllScopes, scope) {
+			return nil, fmt.Errorf("%w: unknown scope %q", ErrInvalidAPIKeyReq, scope)
+		}
+	}
+
+	now := time.Now()
+	active, err := s.APIKeyRepo.CountActive(ctx, user.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	if active >= maxActiveAPIKeys {
+		return nil, ErrTooManyAPIKeys
+	}
+
+	prefix, secret, err := generateAPIKeyParts()
+	if err != nil {
+		return nil, err
+	}
+	plain := fmt.Sprintf("%s_%s_%s", apiKeyPrefix, prefix, secret)
+
+	scopesJSON, _ := json.Marshal(scopes)
+	key := models.APIKey{
+		UserID:    user.ID,
+		Name:      name,
+		Prefix:    prefix,
+		KeyHash:   hashToken(plain),
+		Scopes:    datatypes.JSON(scopesJSON),
+		CreatedAt: now,
+	}
+	if expiresIn > 0 {
+		expiresAt := now.Add(expiresIn)
+		key.ExpiresAt = &expiresAt
+	}
+	if err := s.APIKeyRepo.Create(ctx, &key); err != nil {
+		return nil, err
+	}
+
+	return &CreatedAPIKey{APIKey: key, Key: plain}, nil
+}
+
+func (s *AuthService) ListAPIKeys(ctx context.Context, user *models.User) ([]models.APIKey, error) {
+	return s.APIKeyRepo.ListByUser(ctx, user.ID)
+}
+
+func (s *AuthService) RevokeAPIKey(ctx context.Context, user *models.User, id uuid.UUID) error {
+	revoked, err := s.APIKeyRepo.Revoke(ctx, user.ID, id, time.Now())
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// AuthenticateAPIKey resolves a key of the form lct_<prefix>_<secret> to its owner and scopes
+func (s *AuthService) AuthenticateAPIKey(ctx context.Context, plain string) (*Principal, error) {
+	parts := strings.SplitN(plain, "_", 3)
+	if len(parts) != 3 || parts[0] != apiKeyPrefix {
+		return nil, ErrInvalidAPIKey
+	}
+
+	key, err := s.APIKeyRepo.GetByPrefix(ctx, parts[1])
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if key == nil || key.RevokedAt != nil || (key.ExpiresAt != nil && now.After(*key.ExpiresAt)) {
+		return nil, ErrInvalidAPIKey
+	}
+	if subtle.ConstantTimeCompare([]byte(key.KeyHash), []byte(hashToken(plain))) != 1 {
+		return nil, ErrInvalidAPIKey
+	}
+
+	user, err := s.UserRepo.GetByID(ctx, key.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrInvalidAPIKey
+	}
+
+	var scopes []string
+	if err := json.Unmarshal(key.Scopes, &scopes); err != nil {
+		return nil, fmt.Errorf("failed to parse scopes of API key %s: %w", key.ID, err)
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > apiKeyTouchInterval {
+		if err := s.APIKeyRepo.TouchLastUsed(ctx, key.ID, now); err != nil {
+			log.Printf("Failed to update last_used_at of API key %s: %v", key.ID, err)
+		}
+	}
+
+	return &Principal{User: user, APIKeyID: &key.ID, Scopes: scopes}, nil
+}
+
+func generateAPIKeyParts() (prefix, secret string, err error) {
+	b := make([]byte, 5)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	prefix = strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b))[:apiKeyPrefixLength]
+
+	secret, err = generateRandomToken()
+	if err != nil {
+		return "", "", err
+	}
+	// Keep the key splittable on "_"
+	secret = strings.ReplaceAll(secret, "_", "")
+	return prefix, secret, nil
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/mailer"
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/models"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	emailVerificationTTL = 48 * time.Hour
+	passwordResetTTL     = time.Hour
+	minPasswordLength    = 8
+)
+
+var (
+	ErrInvalidEmailToken = errors.New("invalid or expired link")
+	ErrWeakPassword      = fmt.Errorf("password must be at least %d characters", minPasswordLength)
+)
+
+// SendEmailVerification emails the user a link confirming their address.
+// Any previously issued verification links stop working.
+func (s *AuthService) SendEmailVerification(ctx context.Context, user *models.User) error {
+	if user.EmailVerified {
+		return nil
+	}
+
+	token, err := s.issueEmailToken(ctx, user, models.TokenPurposeEmailVerification, emailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/verify-email?token=%s", s.AppBaseURL, url.QueryEscape(token))
+	return s.Mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Confirm your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nConfirm your email address by opening the link below:\n\n%s\n\nThe link expires in %s.\n",
+			user.Username, link, emailVerificationTTL),
+	})
+}
+
+// VerifyEmail consumes an email verification token and marks the address as verified
+func (s *AuthService) VerifyEmail(ctx context.Context, token string) (*models.User, error) {
+	user, err := s.consumeEmailToken(ctx, models.TokenPurposeEmailVerification, token)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	user.EmailVerified = true
+	user.EmailVerifiedAt = &now
+	if err := s.UserRepo.UpdateColumns(ctx, user.ID, map[string]interface{}{"email_verified": true, "email_verified_at": now}); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// ForgotPassword emails a password reset link if the address belongs to a user.
+// It never reports whether the address exists.
+func (s *AuthService) ForgotPassword(ctx context.Context, email string) error {
+	user, err := s.UserRepo.GetByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return nil
+	}
+
+	token, err := s.issueEmailToken(ctx, user, models.TokenPurposePasswordReset, passwordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", s.AppBaseURL, url.QueryEscape(token))
+	return s.Mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nSomeone requested a password reset for your account. Open the link below to choose a new password:\n\n%s\n\nThe link expires in %s. If you did not request this, you can ignore this email.\n",
+			user.Username, link, passwordResetTTL),
+	})
+}
+
+// ResetPassword consumes a password reset token, sets the new password and signs out every session
+func (s *AuthService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	if err := validatePassword(newPassword); err != nil {
+		return err
+	}
+
+	user, err := s.consumeEmailToken(ctx, models.TokenPurposePasswordReset, token)
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	user.PasswordHash = string(hashedPassword)
+	if err := s.UserRepo.UpdateColumns(ctx, user.ID, map[string]interface{}{"password_hash": user.PasswordHash}); err != nil {
+		return err
+	}
+
+	return s.RefreshTokenRepo.RevokeAllForUser(ctx, user.ID, time.Now())
+}
+
+func (s *AuthService) issueEmailToken(ctx context.Context, user *models.User, purpose string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	if err := s.UserTokenRepo.InvalidateForUser(ctx, user.ID, purpose, now); err != nil {
+		return "", err
+	}
+
+	token, err := generateRandomToken()
+	if err != nil {
+		return "", err
+	}
+
+	stored := &models.UserToken{
+		UserID:    user.ID,
+		Purpose:   purpose,
+		TokenHash: s.signEmailToken(purpose, token),
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+	if err := s.UserTokenRepo.Create(ctx, stored); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (s *AuthService) consumeEmailToken(ctx context.Context, purpose, token string) (*models.User, error) {
+	now := time.Now()
+
+	stored, err := s.UserTokenRepo.GetByHash(ctx, purpose, s.signEmailToken(purpose, token))
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil || stored.UsedAt != nil || now.After(stored.ExpiresAt) {
+		return nil, ErrInvalidEmailToken
+	}
+
+	consumed, err := s.UserTokenRepo.Consume(ctx, stored.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !consumed {
+		return nil, ErrInvalidEmailToken
+	}
+
+	user, err := s.UserRepo.GetByID(ctx, stored.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrInvalidEmailToken
+	}
+	return user, nil
+}
+
+// signEmailToken binds a token to its purpose and the server secret, so leaked hashes cannot be replayed
+func (s *AuthService) signEmailToken(purpose, token string) string {
+	mac := hmac.New(sha256.New, []byte(s.JWTSecret))
+	mac.Write([]byte(purpose + ":" + token))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// trySendEmailVerification logs instead of failing, for callers where email delivery is best effort (e.g. signup)
+func (s *AuthService) trySendEmailVerification(ctx context.Context, user *models.User) {
+	if err := s.SendEmailVerification(ctx, user); err != nil {
+		log.Printf("Failed to send verification email to user %s: %v", user.ID, err)
+	}
+}
+
+func validatePassword(password string) error {
+	if len(password) < minPasswordLength {
+		return ErrWeakPassword
+	}
+	return nil
+}
@@ -2,33 +2,99 @@ package services
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"log"
 	"time"
 
 	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/config"
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/mailer"
 	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/models"
 	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/repository"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type AuthService struct {
-	UserRepo  repository.UserRepository
-	JWTSecret string
+	UserRepo         repository.UserRepository
+	RefreshTokenRepo repository.RefreshTokenRepository
+	UserTokenRepo    repository.UserTokenRepository
+	RecoveryCodeRepo repository.RecoveryCodeRepository
+	APIKeyRepo       repository.APIKeyRepository
+	Guard            *LoginGuard
+	Mailer           mailer.Mailer
+	JWTSecret        string
+	AccessTokenTTL   time.Duration
+	RefreshTokenTTL  time.Duration
+	AppBaseURL       string
+	TOTPIssuer       string
 }
 
-func NewAuthService(userRepo repository.UserRepository, cfg *config.Config) *AuthService {
+func NewAuthService(userRepo repository.UserRepository, refreshTokenRepo repository.RefreshTokenRepository, userTokenRepo repository.UserTokenRepository, recoveryCodeRepo repository.RecoveryCodeRepository, apiKeyRepo repository.APIKeyRepository, guard *LoginGuard, mail mailer.Mailer, cfg *config.Config) *AuthService {
 	return &AuthService{
-		UserRepo:  userRepo,
-		JWTSecret: cfg.JWTSecret,
+		UserRepo:         userRepo,
+		RefreshTokenRepo: refreshTokenRepo,
+		UserTokenRepo:    userTokenRepo,
+		RecoveryCodeRepo: recoveryCodeRepo,
+		APIKeyRepo:       apiKeyRepo,
+		Guard:            guard,
+		Mailer:           mail,
+		JWTSecret:        cfg.JWTSecret,
+		AccessTokenTTL:   cfg.AccessTokenTTL,
+		RefreshTokenTTL:  cfg.RefreshTokenTTL,
+		AppBaseURL:       cfg.AppBaseURL,
+		TOTPIssuer:       cfg.TOTPIssuer,
 	}
 }
 
 type TokenResponse struct {
-	Token string `json:"token"`
+	Token            string    `json:"token"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
 }
 
+// ClientInfo carries device metadata recorded alongside a session
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
+// Principal describes the caller of an authenticated request, either a login session or an API key
+type Principal struct {
+	User      *models.User
+	SessionID uuid.UUID  // Set for session (JWT) principals
+	APIKeyID  *uuid.UUID // Set for API key principals
+	Scopes    []string   // Scopes granted to the API key
+}
+
+// Session is a user-facing view of a refresh token family
+type Session struct {
+	ID         uuid.UUID `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	StartedAt  time.Time `json:"started_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
+
+var (
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrInvalidToken       = errors.New("invalid or expired token")
+	ErrRefreshTokenReuse  = errors.New("refresh token reuse detected, session revoked")
+	ErrSessionNotFound    = errors.New("session not found")
+)
+
 func (s *AuthService) Signup(ctx context.Context, username, email, password string) (*models.User, error) {
+	if err := validatePassword(password); err != nil {
+		return nil, err
+	}
+
 	// Check if user exists
 	existingUser, _ := s.UserRepo.GetByUsername(ctx, username)
 	if existingUser != nil {
@@ -54,16 +120,28 @@ func (s *AuthService) Signup(ctx context.Context, username, email, password stri
 		return nil, err
 	}
 
+	s.trySendEmailVerification(ctx, user)
+
 	return user, nil
 }
 
-func (s *AuthService) Login(ctx context.Context, identifier, password string) (*TokenResponse, error) {
+// Login checks the password and starts a session. Accounts with 2FA get a challenge instead of tokens.
+func (s *AuthService) Login(ctx context.Context, identifier, password string, client ClientInfo) (*LoginResponse, error) {
 	// Try to find user by username
 	user, err := s.UserRepo.GetByUsername(ctx, identifier)
 	// If not found by username, try email. Ideally, repository would have GetByEmail or GetByIdentifier
-	if user == nil {
+	if user == nil && err == nil {
 		user, err = s.UserRepo.GetByEmail(ctx, identifier)
 	}
+	if err != nil {
+		return nil, err
+	}
+
+	attempt := LoginAttempt{Identifier: identifier, User: user, Client: client}
+	if err := s.Guard.Check(ctx, attempt); err != nil {
+		return nil, err
+	}
+
 	// But since UserRepo interface is defined (and typically implemented with GORM), let's assume strict username for now or we update repo.
 	// Since user prompted "signup using his leetcode username , email and password, and login using either email / password or username or password",
 	// I should check if identifier is email.
@@ -72,30 +150,227 @@ func (s *AuthService) Login(ctx context.Context, identifier, password string) (*
 		// Try fetching by email - need to add GetByEmail to repo interface or cheat a bit.
 		// Let's assume I fix repo later or do it here. For now, strict username login if repo method missing.
 		// Wait, I can update repo interface.
-		return nil, errors.New("invalid credentials")
+		s.recordLoginFailure(ctx, attempt, FailureUnknownUser)
+		return nil, ErrInvalidCredentials
 	}
 
 	// Verify password
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
-		return nil, errors.New("invalid credentials")
+		s.recordLoginFailure(ctx, attempt, FailureBadPassword)
+		return nil, ErrInvalidCredentials
 	}
 
-	// Generate JWT
-	token, err := s.generateJWT(user)
+	if user.TwoFactorEnabled {
+		// Counters are only cleared once the second factor is checked too
+		return s.startTwoFactorChallenge(user)
+	}
+	s.recordLoginSuccess(ctx, attempt)
+
+	// Start a new session
+	tokens, err := s.issueTokens(ctx, user, uuid.New(), time.Now(), client)
 	if err != nil {
 		return nil, err
 	}
+	return &LoginResponse{TokenResponse: tokens}, nil
+}
+
+// Refresh rotates a refresh token and returns a fresh token pair for the same session.
+// Presenting an already rotated token revokes the whole session, since it means the token leaked.
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string, client ClientInfo) (*TokenResponse, error) {
+	now := time.Now()
 
-	return &TokenResponse{Token: token}, nil
+	stored, err := s.RefreshTokenRepo.GetByHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil || stored.RevokedAt != nil || now.After(stored.ExpiresAt) {
+		return nil, ErrInvalidToken
+	}
+
+	consumed, err := s.RefreshTokenRepo.Consume(ctx, stored.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !consumed {
+		if err := s.RefreshTokenRepo.RevokeFamily(ctx, stored.FamilyID, now); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReuse
+	}
+
+	user, err := s.UserRepo.GetByID(ctx, stored.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrInvalidToken
+	}
+
+	return s.issueTokens(ctx, user, stored.FamilyID, stored.SessionStartedAt, client)
 }
 
-func (s *AuthService) generateJWT(user *models.User) (string, error) {
+// Logout revokes the session the access token belongs to
+func (s *AuthService) Logout(ctx context.Context, principal *Principal) error {
+	return s.RefreshTokenRepo.RevokeFamily(ctx, principal.SessionID, time.Now())
+}
+
+// ListSessions returns the caller's active sessions, flagging the one making the request
+func (s *AuthService) ListSessions(ctx context.Context, principal *Principal) ([]Session, error) {
+	tokens, err := s.RefreshTokenRepo.ListActive(ctx, principal.User.ID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]Session, 0, len(tokens))
+	for _, t := range tokens {
+		sessions = append(sessions, Session{
+			ID:         t.FamilyID,
+			UserAgent:  t.UserAgent,
+			IPAddress:  t.IPAddress,
+			StartedAt:  t.SessionStartedAt,
+			LastUsedAt: t.CreatedAt,
+			ExpiresAt:  t.ExpiresAt,
+			Current:    t.FamilyID == principal.SessionID,
+		})
+	}
+	return sessions, nil
+}
+
+// RevokeSession kills one of the caller's sessions, e.g. one left open on another device
+func (s *AuthService) RevokeSession(ctx context.Context, principal *Principal, sessionID uuid.UUID) error {
+	sessions, err := s.ListSessions(ctx, principal)
+	if err != nil {
+		return err
+	}
+	for _, session := range sessions {
+		if session.ID == sessionID {
+			return s.RefreshTokenRepo.RevokeFamily(ctx, sessionID, time.Now())
+		}
+	}
+	return ErrSessionNotFound
+}
+
+func (s *AuthService) issueTokens(ctx context.Context, user *models.User, familyID uuid.UUID, sessionStartedAt time.Time, client ClientInfo) (*TokenResponse, error) {
+	now := time.Now()
+	accessExpiresAt := now.Add(s.AccessTokenTTL)
+
+	token, err := s.generateJWT(user, familyID, accessExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := generateRandomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	stored := &models.RefreshToken{
+		UserID:           user.ID,
+		FamilyID:         familyID,
+		TokenHash:        hashToken(refreshToken),
+		UserAgent:        client.UserAgent,
+		IPAddress:        client.IPAddress,
+		SessionStartedAt: sessionStartedAt,
+		ExpiresAt:        now.Add(s.RefreshTokenTTL),
+		CreatedAt:        now,
+	}
+	if err := s.RefreshTokenRepo.Create(ctx, stored); err != nil {
+		return nil, err
+	}
+
+	return &TokenResponse{
+		Token:            token,
+		ExpiresAt:        accessExpiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: stored.ExpiresAt,
+	}, nil
+}
+
+func (s *AuthService) generateJWT(user *models.User, sessionID uuid.UUID, expiresAt time.Time) (string, error) {
 	claims := jwt.MapClaims{
 		"user_id":  user.ID,
 		"username": user.Username,
-		"exp":      time.Now().Add(time.Hour * 72).Unix(), // 72 hours
+		"sid":      sessionID,
+		"typ":      tokenTypeAccess,
+		"exp":      expiresAt.Unix(),
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(s.JWTSecret))
 }
+
+// Authenticate validates a token issued by generateJWT, checks that its session has not been revoked
+// and loads the user it belongs to.
+func (s *AuthService) Authenticate(ctx context.Context, tokenString string) (*Principal, error) {
+	claims, err := s.parseJWT(tokenString)
+	if err != nil || claims["typ"] != tokenTypeAccess {
+		return nil, ErrInvalidToken
+	}
+
+	rawID, _ := claims["user_id"].(string)
+	userID, err := uuid.Parse(rawID)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	rawSessionID, _ := claims["sid"].(string)
+	sessionID, err := uuid.Parse(rawSessionID)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	active, err := s.RefreshTokenRepo.IsFamilyActive(ctx, sessionID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !active {
+		return nil, ErrInvalidToken
+	}
+
+	user, err := s.UserRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrInvalidToken
+	}
+
+	return &Principal{User: user, SessionID: sessionID}, nil
+}
+
+func (s *AuthService) recordLoginFailure(ctx context.Context, attempt LoginAttempt, reason string) {
+	if err := s.Guard.Fail(ctx, attempt, reason); err != nil {
+		log.Printf("Failed to record login failure for %q: %v", attempt.Identifier, err)
+	}
+}
+
+func (s *AuthService) recordLoginSuccess(ctx context.Context, attempt LoginAttempt) {
+	if err := s.Guard.Succeed(ctx, attempt); err != nil {
+		log.Printf("Failed to reset login failures for %q: %v", attempt.Identifier, err)
+	}
+}
+
+// parseJWT verifies the signature and expiry of a token signed with the server secret
+func (s *AuthService) parseJWT(tokenString string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		return []byte(s.JWTSecret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// generateRandomToken returns a URL-safe random string suitable for opaque tokens
+func generateRandomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,259 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/models"
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/totp"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	challengeTokenTTL = 5 * time.Minute
+	recoveryCodeCount = 10
+	totpSkew          = 1 // Accept codes from one period before/after to absorb clock drift
+
+	tokenTypeAccess    = "access"
+	tokenTypeChallenge = "2fa_challenge"
+)
+
+var (
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnrolled    = errors.New("start two-factor enrollment first")
+	ErrTwoFactorNotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrInvalidTwoFactorCode    = errors.New("invalid two-factor code")
+)
+
+// LoginResponse is either a token pair or, for accounts with 2FA, a challenge to exchange at /auth/login/2fa
+type LoginResponse struct {
+	*TokenResponse
+	TwoFactorRequired  bool       `json:"two_factor_required,omitempty"`
+	ChallengeToken     string     `json:"challenge_token,omitempty"`
+	ChallengeExpiresAt *time.Time `json:"challenge_expires_at,omitempty"`
+}
+
+// TwoFactorEnrollment holds what an authenticator app needs to be set up
+type TwoFactorEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// RecoveryCodes are shown to the user exactly once; only their hashes are stored
+type RecoveryCodes struct {
+	Codes []string `json:"recovery_codes"`
+}
+
+// EnrollTwoFactor generates a new TOTP secret for the user. It is not enforced until ConfirmTwoFactor.
+func (s *AuthService) EnrollTwoFactor(ctx context.Context, user *models.User) (*TwoFactorEnrollment, error) {
+	if user.TwoFactorEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	user.TOTPSecret = secret
+	user.TOTPLastUsedStep = 0
+	if err := s.UserRepo.UpdateColumns(ctx, user.ID, map[string]interface{}{"totp_secret": secret, "totp_last_used_step": 0}); err != nil {
+		return nil, err
+	}
+
+	return &TwoFactorEnrollment{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(s.TOTPIssuer, user.Username, secret),
+	}, nil
+}
+
+// ConfirmTwoFactor enables 2FA once the user proves their authenticator produces valid codes
+func (s *AuthService) ConfirmTwoFactor(ctx context.Context, user *models.User, code string) (*RecoveryCodes, error) {
+	if user.TwoFactorEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, ErrTwoFactorNotEnrolled
+	}
+	if err := s.checkTOTP(ctx, user, code); err != nil {
+		return nil, err
+	}
+
+	user.TwoFactorEnabled = true
+	if err := s.UserRepo.UpdateColumns(ctx, user.ID, map[string]interface{}{"two_factor_enabled": true}); err != nil {
+		return nil, err
+	}
+
+	return s.regenerateRecoveryCodes(ctx, user)
+}
+
+// RegenerateRecoveryCodes invalidates the old recovery codes and issues a new set
+func (s *AuthService) RegenerateRecoveryCodes(ctx context.Context, user *models.User, code string) (*RecoveryCodes, error) {
+	if !user.TwoFactorEnabled {
+		return nil, ErrTwoFactorNotEnabled
+	}
+	if err := s.checkTOTP(ctx, user, code); err != nil {
+		return nil, err
+	}
+	return s.regenerateRecoveryCodes(ctx, user)
+}
+
+// DisableTwoFactor turns 2FA off after re-checking the password and a current code
+func (s *AuthService) DisableTwoFactor(ctx context.Context, user *models.User, password, code string) error {
+	if !user.TwoFactorEnabled {
+		return ErrTwoFactorNotEnabled
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return ErrInvalidCredentials
+	}
+	if err := s.checkTOTP(ctx, user, code); err != nil {
+		return err
+	}
+
+	user.TwoFactorEnabled = false
+	user.TOTPSecret = ""
+	user.TOTPLastUsedStep = 0
+	err := s.UserRepo.UpdateColumns(ctx, user.ID, map[string]interface{}{
+		"two_factor_enabled":  false,
+		"totp_secret":         "",
+		"totp_last_used_step": 0,
+	})
+	if err != nil {
+		return err
+	}
+	return s.RecoveryCodeRepo.DeleteForUser(ctx, user.ID)
+}
+
+// CompleteTwoFactorLogin exchanges a login challenge plus a TOTP or recovery code for a token pair
+func (s *AuthService) CompleteTwoFactorLogin(ctx context.Context, challengeToken, code, recoveryCode string, client ClientInfo) (*TokenResponse, error) {
+	user, err := s.parseChallengeToken(ctx, challengeToken)
+	if err != nil {
+		return nil, err
+	}
+
+	attempt := LoginAttempt{Identifier: user.Username, User: user, Client: client}
+	if err := s.Guard.Check(ctx, attempt); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case code != "":
+		err = s.checkTOTP(ctx, user, code)
+	case recoveryCode != "":
+		consumed, consumeErr := s.RecoveryCodeRepo.Consume(ctx, user.ID, hashToken(normalizeRecoveryCode(recoveryCode)), time.Now())
+		if consumeErr != nil {
+			return nil, consumeErr
+		}
+		if !consumed {
+			err = ErrInvalidTwoFactorCode
+		}
+	default:
+		err = ErrInvalidTwoFactorCode
+	}
+	if err != nil {
+		if errors.Is(err, ErrInvalidTwoFactorCode) {
+			s.recordLoginFailure(ctx, attempt, FailureBad2FACode)
+		}
+		return nil, err
+	}
+	s.recordLoginSuccess(ctx, attempt)
+
+	return s.issueTokens(ctx, user, uuid.New(), time.Now(), client)
+}
+
+// startTwoFactorChallenge is the first login step for accounts with 2FA enabled
+func (s *AuthService) startTwoFactorChallenge(user *models.User) (*LoginResponse, error) {
+	expiresAt := time.Now().Add(challengeTokenTTL)
+	claims := jwt.MapClaims{
+		"user_id": user.ID,
+		"typ":     tokenTypeChallenge,
+		"exp":     expiresAt.Unix(),
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(s.JWTSecret))
+	if err != nil {
+		return nil, err
+	}
+
+	return &LoginResponse{
+		TwoFactorRequired:  true,
+		ChallengeToken:     token,
+		ChallengeExpiresAt: &expiresAt,
+	}, nil
+}
+
+func (s *AuthService) parseChallengeToken(ctx context.Context, tokenString string) (*models.User, error) {
+	claims, err := s.parseJWT(tokenString)
+	if err != nil || claims["typ"] != tokenTypeChallenge {
+		return nil, ErrInvalidToken
+	}
+
+	rawID, _ := claims["user_id"].(string)
+	userID, err := uuid.Parse(rawID)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	user, err := s.UserRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || !user.TwoFactorEnabled {
+		return nil, ErrInvalidToken
+	}
+	return user, nil
+}
+
+// checkTOTP validates a code and records its time step so the same code cannot be used twice
+func (s *AuthService) checkTOTP(ctx context.Context, user *models.User, code string) error {
+	step, ok := totp.Validate(user.TOTPSecret, code, time.Now(), totpSkew)
+	if !ok || step <= user.TOTPLastUsedStep {
+		return ErrInvalidTwoFactorCode
+	}
+
+	user.TOTPLastUsedStep = step
+	return s.UserRepo.UpdateColumns(ctx, user.ID, map[string]interface{}{"totp_last_used_step": step})
+}
+
+func (s *AuthService) regenerateRecoveryCodes(ctx context.Context, user *models.User) (*RecoveryCodes, error) {
+	now := time.Now()
+	codes := make([]string, 0, recoveryCodeCount)
+	stored := make([]models.RecoveryCode, 0, recoveryCodeCount)
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		stored = append(stored, models.RecoveryCode{
+			UserID:    user.ID,
+			CodeHash:  hashToken(normalizeRecoveryCode(code)),
+			CreatedAt: now,
+		})
+	}
+
+	if err := s.RecoveryCodeRepo.ReplaceForUser(ctx, user.ID, stored); err != nil {
+		return nil, err
+	}
+	return &RecoveryCodes{Codes: codes}, nil
+}
+
+// generateRecoveryCode returns a code like "k3p9x-2mq7d"
+func generateRecoveryCode() (string, error) {
+	b := make([]byte, 7)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	raw := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b))[:10]
+	return raw[:5] + "-" + raw[5:], nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/models"
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/repository"
+	"github.com/google/uuid"
+)
+
+// Fakes embed the repository interfaces so only the methods a test reaches need a body
+
+type fakeUserRepo struct {
+	repository.UserRepository
+	users map[uuid.UUID]*models.User
+}
+
+func (r *fakeUserRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	return r.users[id], nil
+}
+
+func (r *fakeUserRepo) Update(ctx context.Context, user *models.User) error {
+	r.users[user.ID] = user
+	return nil
+}
+
+type fakeRecoveryCodeRepo struct {
+	repository.RecoveryCodeRepository
+	codes []models.RecoveryCode
+}
+
+func (r *fakeRecoveryCodeRepo) ReplaceForUser(ctx context.Context, userID uuid.UUID, codes []models.RecoveryCode) error {
+	r.codes = codes
+	return nil
+}
+
+func (r *fakeRecoveryCodeRepo) Consume(ctx context.Context, userID uuid.UUID, codeHash string, at time.Time) (bool, error) {
+	for i := range r.codes {
+		code := &r.codes[i]
+		if code.UserID == userID && code.CodeHash == codeHash && code.UsedAt == nil {
+			code.UsedAt = &at
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+type fakeRefreshTokenRepo struct {
+	repository.RefreshTokenRepository
+}
+
+func (fakeRefreshTokenRepo) Create(ctx context.Context, token *models.RefreshToken) error {
+	return nil
+}
+
+type fakeFailedLoginRepo struct {
+	repository.FailedLoginRepository
+	entries []models.FailedLogin
+}
+
+func (r *fakeFailedLoginRepo) Create(ctx context.Context, entry *models.FailedLogin) error {
+	r.entries = append(r.entries, *entry)
+	return nil
+}
+
+func newTestAuthService(user *models.User) (*AuthService, *fakeFailedLoginRepo) {
+	failed := &fakeFailedLoginRepo{}
+	return &AuthService{
+		UserRepo:         &fakeUserRepo{users: map[uuid.UUID]*models.User{user.ID: user}},
+		RefreshTokenRepo: fakeRefreshTokenRepo{},
+		RecoveryCodeRepo: &fakeRecoveryCodeRepo{},
+		Guard: &LoginGuard{
+			Store:           repository.NewMemoryLoginAttemptStore(),
+			FailedLoginRepo: failed,
+			MaxFailures:     5,
+			MaxIPFailures:   20,
+			LockoutBase:     time.Minute,
+			LockoutMax:      time.Hour,
+			FailureWindow:   time.Hour,
+		},
+		JWTSecret:       "test-secret",
+		AccessTokenTTL:  time.Minute,
+		RefreshTokenTTL: time.Hour,
+	}, failed
+}
+
+func TestRecoveryCodeCannotBeUsedTwice(t *testing.T) {
+	ctx := context.Background()
+	user := &models.User{ID: uuid.New(), Username: "alice", TwoFactorEnabled: true}
+	s, failed := newTestAuthService(user)
+
+	codes, err := s.regenerateRecoveryCodes(ctx, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	challenge := func() string {
+		resp, err := s.startTwoFactorChallenge(user)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.ChallengeToken
+	}
+
+	if _, err := s.CompleteTwoFactorLogin(ctx, challenge(), "", codes.Codes[0], ClientInfo{}); err != nil {
+		t.Fatalf("first use of a recovery code failed: %v", err)
+	}
+
+	_, err = s.CompleteTwoFactorLogin(ctx, challenge(), "", codes.Codes[0], ClientInfo{})
+	if !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Fatalf("second use of a recovery code: err = %v, want %v", err, ErrInvalidTwoFactorCode)
+	}
+	if len(failed.entries) != 1 || failed.entries[0].Reason != FailureBad2FACode {
+		t.Errorf("reused recovery code was not audited as %s: %+v", FailureBad2FACode, failed.entries)
+	}
+
+	// Other codes stay usable
+	if _, err := s.CompleteTwoFactorLogin(ctx, challenge(), "", codes.Codes[1], ClientInfo{}); err != nil {
+		t.Errorf("an unused recovery code was rejected: %v", err)
+	}
+}
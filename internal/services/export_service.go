@@ -0,0 +1,470 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/config"
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/models"
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/repository"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrExportNotFound     = errors.New("export not found")
+	ErrInvalidExportLink  = errors.New("invalid or expired download link")
+	ErrExportNotAvailable = errors.New("export has expired or failed")
+)
+
+// comparisonHistory is the part of ComparisonRepository exports read
+type comparisonHistory interface {
+	ListByUsername(username string) ([]models.UserComparison, error)
+	CountByUsername(username string) (int64, error)
+}
+
+// ExportService builds GDPR-style archives (zip of JSON + CSV) of everything stored about a user
+type ExportService struct {
+	UserRepo          repository.UserRepository
+	GoalRepo          repository.GoalRepository
+	ActivityRepo      repository.ActivityRepository
+	ComparisonRepo    comparisonHistory
+	SnapshotRepo      repository.StatSnapshotRepository
+	SyncEventRepo     repository.SyncEventRepository
+	DailyActivityRepo repository.DailyActivityRepository
+	SolvedRepo        repository.SolvedProblemRepository
+	PreferencesRepo   repository.GoalPreferencesRepository
+	ExportRepo        repository.DataExportRepository
+	Dir               string
+	LinkTTL           time.Duration
+	SyncMaxRows       int64
+	StaleAfter        time.Duration
+	Secret            string
+}
+
+func NewExportService(userRepo repository.UserRepository, goalRepo repository.GoalRepository, activityRepo repository.ActivityRepository, comparisonRepo *repository.ComparisonRepository, snapshotRepo repository.StatSnapshotRepository, syncEventRepo repository.SyncEventRepository, dailyActivityRepo repository.DailyActivityRepository, solvedRepo repository.SolvedProblemRepository, preferencesRepo repository.GoalPreferencesRepository, exportRepo repository.DataExportRepository, cfg *config.Config) *ExportService {
+	return &ExportService{
+		UserRepo:          userRepo,
+		GoalRepo:          goalRepo,
+		ActivityRepo:      activityRepo,
+		ComparisonRepo:    comparisonRepo,
+		SnapshotRepo:      snapshotRepo,
+		SyncEventRepo:     syncEventRepo,
+		DailyActivityRepo: dailyActivityRepo,
+		SolvedRepo:        solvedRepo,
+		PreferencesRepo:   preferencesRepo,
+		ExportRepo:        exportRepo,
+		Dir:               cfg.ExportDir,
+		LinkTTL:           cfg.ExportLinkTTL,
+		SyncMaxRows:       cfg.ExportSyncMaxRows,
+		StaleAfter:        cfg.ExportStaleAfter,
+		Secret:            cfg.JWTSecret,
+	}
+}
+
+// ExportStatus is a DataExport plus a signed download path once the archive is ready
+type ExportStatus struct {
+	models.DataExport
+	DownloadPath string `json:"download_path,omitempty"`
+}
+
+// exportData is everything that goes into an archive
+type exportData struct {
+	User          *models.User
+	Goals         []models.WeeklyGoal
+	Archives      []models.WeeklyGoalArchive // Goals moved out of weekly_goals by retention cleanup
+	Activity      []models.ActivityLog
+	Comparisons   []models.UserComparison
+	Snapshots     []models.UserStatSnapshot
+	SyncEvents    []models.SyncEvent
+	DailyActivity []models.UserDailyActivity
+	Solved        []models.UserSolvedProblem
+	Preferences   *models.UserGoalPreferences // Nil when the user never saved any
+}
+
+// IsLarge reports whether the user's data should be exported in the background
+func (s *ExportService) IsLarge(ctx context.Context, user *models.User) (bool, error) {
+	goals, err := s.GoalRepo.CountByUser(ctx, user.ID)
+	if err != nil {
+		return false, err
+	}
+	archives, err := s.GoalRepo.CountArchivedByUser(ctx, user.ID)
+	if err != nil {
+		return false, err
+	}
+	activity, err := s.ActivityRepo.CountByUser(ctx, user.ID)
+	if err != nil {
+		return false, err
+	}
+	comparisons, err := s.ComparisonRepo.CountByUsername(user.Username)
+	if err != nil {
+		return false, err
+	}
+	syncEvents, err := s.SyncEventRepo.CountByUser(ctx, user.ID)
+	if err != nil {
+		return false, err
+	}
+	days, err := s.DailyActivityRepo.CountByUser(ctx, user.ID)
+	if err != nil {
+		return false, err
+	}
+	solved, err := s.SolvedRepo.CountByUser(ctx, user.ID)
+	if err != nil {
+		return false, err
+	}
+	return goals+archives+activity+comparisons+syncEvents+days+solved > s.SyncMaxRows, nil
+}
+
+// WriteArchive streams the user's archive to w
+func (s *ExportService) WriteArchive(ctx context.Context, user *models.User, w io.Writer) error {
+	data, err := s.collect(ctx, user)
+	if err != nil {
+		return err
+	}
+	return writeArchive(w, data)
+}
+
+// StartExport queues a background export, reusing one that is already in progress
+func (s *ExportService) StartExport(ctx context.Context, user *models.User) (*ExportStatus, error) {
+	// An export abandoned by a crash would otherwise block new ones forever
+	if err := s.failStale(ctx); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.ExportRepo.GetInProgress(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return &ExportStatus{DataExport: *existing}, nil
+	}
+
+	export := &models.DataExport{
+		UserID:    user.ID,
+		Status:    models.ExportStatusPending,
+		CreatedAt: time.Now(),
+	}
+	if err := s.ExportRepo.Create(ctx, export); err != nil {
+		return nil, err
+	}
+
+	// The request context ends with the response, so the export gets its own
+	go s.runExport(context.Background(), *export, user)
+
+	return &ExportStatus{DataExport: *export}, nil
+}
+
+// GetStatus returns one of the user's exports, with a fresh signed download path if it is ready
+func (s *ExportService) GetStatus(ctx context.Context, user *models.User, id uuid.UUID) (*ExportStatus, error) {
+	export, err := s.ExportRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if export == nil || export.UserID != user.ID {
+		return nil, ErrExportNotFound
+	}
+
+	status := &ExportStatus{DataExport: *export}
+	if export.Status == models.ExportStatusCompleted && export.ExpiresAt != nil && time.Now().Before(*export.ExpiresAt) {
+		expires := export.ExpiresAt.Unix()
+		status.DownloadPath = fmt.Sprintf("/api/v1/exports/%s/download?expires=%d&signature=%s", export.ID, expires, s.signDownload(export.ID, expires))
+	}
+	return status, nil
+}
+
+// OpenDownload checks a signed download link and opens the archive it points to
+func (s *ExportService) OpenDownload(ctx context.Context, id uuid.UUID, expires int64, signature string) (*os.File, *models.DataExport, error) {
+	expected := s.signDownload(id, expires)
+	if !hmac.Equal([]byte(expected), []byte(signature)) || time.Now().Unix() > expires {
+		return nil, nil, ErrInvalidExportLink
+	}
+
+	export, err := s.ExportRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if export == nil || export.Status != models.ExportStatusCompleted || export.ExpiresAt == nil || time.Now().After(*export.ExpiresAt) {
+		return nil, nil, ErrExportNotAvailable
+	}
+
+	f, err := os.Open(export.FilePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open export archive: %w", err)
+	}
+	return f, export, nil
+}
+
+// CleanupExpired fails abandoned exports and deletes archives whose download window has passed
+func (s *ExportService) CleanupExpired(ctx context.Context) error {
+	if err := s.failStale(ctx); err != nil {
+		return err
+	}
+
+	exports, err := s.ExportRepo.ListExpired(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, export := range exports {
+		if err := s.removeArchive(export); err != nil {
+			log.Printf("Failed to remove archive of export %s: %v", export.ID, err)
+			continue
+		}
+		if err := s.ExportRepo.Delete(ctx, export.ID); err != nil {
+			log.Printf("Failed to delete export %s: %v", export.ID, err)
+		}
+	}
+	return nil
+}
+
+// DeleteUserExports removes the archives of all of the user's exports. It runs before the
+// account is purged, which deletes the export rows.
+func (s *ExportService) DeleteUserExports(ctx context.Context, user *models.User) error {
+	exports, err := s.ExportRepo.ListByUser(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, export := range exports {
+		if err := s.removeArchive(export); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeArchive deletes the export's archive. Exports that never completed have no FilePath
+// but may have left a partial archive at the path they were writing to.
+func (s *ExportService) removeArchive(export models.DataExport) error {
+	paths := []string{s.archivePath(export.ID)}
+	if export.FilePath != "" && export.FilePath != paths[0] {
+		paths = append(paths, export.FilePath)
+	}
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove export archive %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+func (s *ExportService) archivePath(id uuid.UUID) string {
+	return filepath.Join(s.Dir, id.String()+".zip")
+}
+
+func (s *ExportService) failStale(ctx context.Context) error {
+	now := time.Now()
+	if _, err := s.ExportRepo.FailStale(ctx, now.Add(-s.StaleAfter), now.Add(s.LinkTTL)); err != nil {
+		return fmt.Errorf("failed to fail stale exports: %w", err)
+	}
+	return nil
+}
+
+func (s *ExportService) runExport(ctx context.Context, export models.DataExport, user *models.User) {
+	export.Status = models.ExportStatusRunning
+	if err := s.ExportRepo.Update(ctx, &export); err != nil {
+		log.Printf("Failed to mark export %s as running: %v", export.ID, err)
+	}
+
+	path, size, err := s.buildArchiveFile(ctx, export.ID, user)
+	now := time.Now()
+	if err != nil {
+		log.Printf("Export %s failed: %v", export.ID, err)
+		expiresAt := now.Add(s.LinkTTL)
+		export.Status = models.ExportStatusFailed
+		export.Error = err.Error()
+		export.ExpiresAt = &expiresAt
+	} else {
+		expiresAt := now.Add(s.LinkTTL)
+		export.Status = models.ExportStatusCompleted
+		export.FilePath = path
+		export.SizeBytes = size
+		export.CompletedAt = &now
+		export.ExpiresAt = &expiresAt
+	}
+
+	if err := s.ExportRepo.Update(ctx, &export); err != nil {
+		log.Printf("Failed to save export %s: %v", export.ID, err)
+	}
+}
+
+func (s *ExportService) buildArchiveFile(ctx context.Context, id uuid.UUID, user *models.User) (string, int64, error) {
+	if err := os.MkdirAll(s.Dir, 0o700); err != nil {
+		return "", 0, fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	path := s.archivePath(id)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create export archive: %w", err)
+	}
+	defer f.Close()
+
+	if err := s.WriteArchive(ctx, user, f); err != nil {
+		os.Remove(path)
+		return "", 0, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", 0, err
+	}
+	return path, info.Size(), nil
+}
+
+func (s *ExportService) collect(ctx context.Context, user *models.User) (*exportData, error) {
+	goals, err := s.GoalRepo.ListByUser(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load goals: %w", err)
+	}
+	archives, err := s.GoalRepo.ListAllArchived(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load archived goals: %w", err)
+	}
+	activity, err := s.ActivityRepo.ListByUser(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load activity: %w", err)
+	}
+	comparisons, err := s.ComparisonRepo.ListByUsername(user.Username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load comparisons: %w", err)
+	}
+
+	snapshots, err := s.SnapshotRepo.ListByUser(ctx, user.ID, time.Time{}, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load stat history: %w", err)
+	}
+	syncEvents, err := s.SyncEventRepo.ListAllByUser(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load sync events: %w", err)
+	}
+	days, err := s.DailyActivityRepo.ListByUser(ctx, user.ID, time.Time{}, time.Time{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load daily activity: %w", err)
+	}
+	solved, err := s.SolvedRepo.ListFullByUser(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load solved problems: %w", err)
+	}
+	prefs, err := s.PreferencesRepo.GetByUser(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load goal preferences: %w", err)
+	}
+
+	return &exportData{
+		User: user, Goals: goals, Archives: archives, Activity: activity, Comparisons: comparisons, Snapshots: snapshots,
+		SyncEvents: syncEvents, DailyActivity: days, Solved: solved, Preferences: prefs,
+	}, nil
+}
+
+func (s *ExportService) signDownload(id uuid.UUID, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(s.Secret))
+	mac.Write([]byte(fmt.Sprintf("export:%s:%d", id, expires)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func writeArchive(w io.Writer, data *exportData) error {
+	zw := zip.NewWriter(w)
+
+	jsonFiles := map[string]interface{}{
+		"user.json":                 data.User,
+		"weekly_goals.json":         data.Goals,
+		"weekly_goal_archives.json": data.Archives,
+		"activity_log.json":         data.Activity,
+		"comparisons.json":          data.Comparisons,
+		"stat_history.json":         data.Snapshots,
+		"sync_events.json":          data.SyncEvents,
+		"daily_activity.json":       data.DailyActivity,
+		"solved_problems.json":      data.Solved,
+		"goal_preferences.json":     data.Preferences,
+	}
+	// Map order is random, so write files in name order to keep archives stable
+	for _, name := range slices.Sorted(maps.Keys(jsonFiles)) {
+		v := jsonFiles[name]
+		fw, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(fw)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(v); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+
+	goalRows := [][]string{{"id", "week_start_date", "week_timezone", "goal_type", "status", "completion_percent", "difficulty_breakdown", "selected_problems", "focus_topics", "created_at", "updated_at"}}
+	for _, g := range data.Goals {
+		goalRows = append(goalRows, []string{
+			g.ID.String(), g.WeekStartDate.Format(dateLayout), g.WeekTimezone, g.GoalType, g.Status,
+			strconv.FormatFloat(g.CompletionPercent, 'f', 2, 64),
+			string(g.DifficultyBreakdown), string(g.SelectedProblems), string(g.FocusTopics),
+			g.CreatedAt.Format(time.RFC3339), g.UpdatedAt.Format(time.RFC3339),
+		})
+	}
+
+	archiveRows := [][]string{{"id", "week_start_date", "week_timezone", "goal_type", "status", "completion_percent", "difficulty_breakdown", "selected_problems", "focus_topics", "created_at", "updated_at", "archived_at"}}
+	for _, g := range data.Archives {
+		archiveRows = append(archiveRows, []string{
+			g.ID.String(), g.WeekStartDate.Format(dateLayout), g.WeekTimezone, g.GoalType, g.Status,
+			strconv.FormatFloat(g.CompletionPercent, 'f', 2, 64),
+			string(g.DifficultyBreakdown), string(g.SelectedProblems), string(g.FocusTopics),
+			g.CreatedAt.Format(time.RFC3339), g.UpdatedAt.Format(time.RFC3339), g.ArchivedAt.Format(time.RFC3339),
+		})
+	}
+
+	activityRows := [][]string{{"id", "activity_type", "reference_id", "timestamp"}}
+	for _, a := range data.Activity {
+		activityRows = append(activityRows, []string{a.ID.String(), a.ActivityType, a.ReferenceID, a.Timestamp.Format(time.RFC3339)})
+	}
+
+	comparisonRows := [][]string{{"id", "user1_name", "user2_name", "result", "created_at"}}
+	for _, c := range data.Comparisons {
+		comparisonRows = append(comparisonRows, []string{c.ID.String(), c.User1Name, c.User2Name, string(c.Result), c.CreatedAt.Format(time.RFC3339)})
+	}
+
+	dayRows := [][]string{{"date", "submissions"}}
+	for _, d := range data.DailyActivity {
+		dayRows = append(dayRows, []string{d.Date.Format(dateLayout), strconv.Itoa(d.Submissions)})
+	}
+
+	solvedRows := [][]string{{"title_slug", "title", "language", "first_solved_at", "last_solved_at"}}
+	for _, p := range data.Solved {
+		solvedRows = append(solvedRows, []string{p.TitleSlug, p.Title, p.Language, p.FirstSolvedAt.Format(time.RFC3339), p.LastSolvedAt.Format(time.RFC3339)})
+	}
+
+	csvFiles := map[string][][]string{
+		"weekly_goals.csv":         goalRows,
+		"weekly_goal_archives.csv": archiveRows,
+		"activity_log.csv":         activityRows,
+		"comparisons.csv":          comparisonRows,
+		"daily_activity.csv":       dayRows,
+		"solved_problems.csv":      solvedRows,
+	}
+	for _, name := range slices.Sorted(maps.Keys(csvFiles)) {
+		rows := csvFiles[name]
+		fw, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		cw := csv.NewWriter(fw)
+		if err := cw.WriteAll(rows); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+
+	return zw.Close()
+}
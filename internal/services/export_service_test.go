@@ -0,0 +1,241 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/models"
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/repository"
+	"github.com/google/uuid"
+)
+
+// fakeGoalRepo keeps live and archived goals in memory and archives like the real repository
+type fakeGoalRepo struct {
+	repository.GoalRepository
+	goals    []models.WeeklyGoal
+	archives []models.WeeklyGoalArchive
+}
+
+func (r *fakeGoalRepo) ListByUser(ctx context.Context, userID uuid.UUID) ([]models.WeeklyGoal, error) {
+	var goals []models.WeeklyGoal
+	for _, g := range r.goals {
+		if g.UserID == userID {
+			goals = append(goals, g)
+		}
+	}
+	return goals, nil
+}
+
+func (r *fakeGoalRepo) CountByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	goals, _ := r.ListByUser(ctx, userID)
+	return int64(len(goals)), nil
+}
+
+func (r *fakeGoalRepo) ArchiveAndDelete(ctx context.Context, userID *uuid.UUID, keepFrom, keepUntil time.Time, archive bool) (int64, error) {
+	var kept []models.WeeklyGoal
+	var removed int64
+	for _, g := range r.goals {
+		old := g.WeekStartDate.Before(keepFrom) && g.Status != models.GoalStatusPending
+		if (userID == nil || g.UserID == *userID) && (old || g.WeekStartDate.After(keepUntil)) {
+			if archive {
+				r.archives = append(r.archives, models.NewWeeklyGoalArchive(g, time.Now()))
+			}
+			removed++
+			continue
+		}
+		kept = append(kept, g)
+	}
+	r.goals = kept
+	return removed, nil
+}
+
+func (r *fakeGoalRepo) ListAllArchived(ctx context.Context, userID uuid.UUID) ([]models.WeeklyGoalArchive, error) {
+	var archives []models.WeeklyGoalArchive
+	for _, a := range r.archives {
+		if a.UserID == userID {
+			archives = append(archives, a)
+		}
+	}
+	return archives, nil
+}
+
+func (r *fakeGoalRepo) CountArchivedByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	archives, _ := r.ListAllArchived(ctx, userID)
+	return int64(len(archives)), nil
+}
+
+// The remaining fakes hold no data for the user
+
+type fakeActivityRepo struct{ repository.ActivityRepository }
+
+func (fakeActivityRepo) ListByUser(ctx context.Context, userID uuid.UUID) ([]models.ActivityLog, error) {
+	return nil, nil
+}
+
+func (fakeActivityRepo) CountByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return 0, nil
+}
+
+type fakeComparisonHistory struct{}
+
+func (fakeComparisonHistory) ListByUsername(username string) ([]models.UserComparison, error) {
+	return nil, nil
+}
+
+func (fakeComparisonHistory) CountByUsername(username string) (int64, error) {
+	return 0, nil
+}
+
+type fakeSnapshotRepo struct {
+	repository.StatSnapshotRepository
+}
+
+func (fakeSnapshotRepo) ListByUser(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]models.UserStatSnapshot, error) {
+	return nil, nil
+}
+
+type fakeSyncEventRepo struct{ repository.SyncEventRepository }
+
+func (fakeSyncEventRepo) ListAllByUser(ctx context.Context, userID uuid.UUID) ([]models.SyncEvent, error) {
+	return nil, nil
+}
+
+func (fakeSyncEventRepo) CountByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return 0, nil
+}
+
+type fakeDailyActivityRepo struct {
+	repository.DailyActivityRepository
+}
+
+func (fakeDailyActivityRepo) ListByUser(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]models.UserDailyActivity, error) {
+	return nil, nil
+}
+
+func (fakeDailyActivityRepo) CountByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return 0, nil
+}
+
+type fakeSolvedRepo struct {
+	repository.SolvedProblemRepository
+}
+
+func (fakeSolvedRepo) ListFullByUser(ctx context.Context, userID uuid.UUID) ([]models.UserSolvedProblem, error) {
+	return nil, nil
+}
+
+func (fakeSolvedRepo) CountByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return 0, nil
+}
+
+type fakePreferencesRepo struct {
+	repository.GoalPreferencesRepository
+}
+
+func (fakePreferencesRepo) GetByUser(ctx context.Context, userID uuid.UUID) (*models.UserGoalPreferences, error) {
+	return nil, nil
+}
+
+func TestExportIncludesArchivedGoals(t *testing.T) {
+	ctx := context.Background()
+	user := &models.User{ID: uuid.New(), Username: "alice"}
+	now := time.Date(2026, 6, 17, 12, 0, 0, 0, time.UTC)
+
+	oldGoal := models.WeeklyGoal{
+		ID: uuid.New(), UserID: user.ID, WeekStartDate: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC),
+		WeekTimezone: "UTC", GoalType: models.GoalTypeGenerated, Status: models.GoalStatusCompleted,
+	}
+	currentGoal := models.WeeklyGoal{
+		ID: uuid.New(), UserID: user.ID, WeekStartDate: time.Date(2026, 6, 15, 0, 0, 0, 0, time.UTC),
+		WeekTimezone: "UTC", GoalType: models.GoalTypeGenerated, Status: models.GoalStatusPending,
+	}
+	goalRepo := &fakeGoalRepo{goals: []models.WeeklyGoal{oldGoal, currentGoal}}
+
+	goals := &GoalService{
+		GoalRepo:           goalRepo,
+		RetentionPastWeeks: 4,
+		RetentionNextWeeks: 4,
+		ArchiveEnabled:     true,
+		Now:                func() time.Time { return now },
+	}
+	if err := goals.CleanupOldGoals(ctx, user); err != nil {
+		t.Fatalf("CleanupOldGoals: %v", err)
+	}
+	if len(goalRepo.goals) != 1 || len(goalRepo.archives) != 1 {
+		t.Fatalf("cleanup left %d live and %d archived goals, want 1 and 1", len(goalRepo.goals), len(goalRepo.archives))
+	}
+
+	exports := &ExportService{
+		GoalRepo:          goalRepo,
+		ActivityRepo:      fakeActivityRepo{},
+		ComparisonRepo:    fakeComparisonHistory{},
+		SnapshotRepo:      fakeSnapshotRepo{},
+		SyncEventRepo:     fakeSyncEventRepo{},
+		DailyActivityRepo: fakeDailyActivityRepo{},
+		SolvedRepo:        fakeSolvedRepo{},
+		PreferencesRepo:   fakePreferencesRepo{},
+		SyncMaxRows:       1,
+	}
+
+	// One live goal plus one archived goal is over the limit of 1
+	large, err := exports.IsLarge(ctx, user)
+	if err != nil {
+		t.Fatalf("IsLarge: %v", err)
+	}
+	if !large {
+		t.Error("IsLarge = false, want archived goals counted")
+	}
+
+	var buf bytes.Buffer
+	if err := exports.WriteArchive(ctx, user, &buf); err != nil {
+		t.Fatalf("WriteArchive: %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("failed to open archive: %v", err)
+	}
+
+	var archived []models.WeeklyGoalArchive
+	if err := json.Unmarshal(readZipFile(t, zr, "weekly_goal_archives.json"), &archived); err != nil {
+		t.Fatalf("failed to decode weekly_goal_archives.json: %v", err)
+	}
+	if len(archived) != 1 || archived[0].ID != oldGoal.ID {
+		t.Errorf("weekly_goal_archives.json = %+v, want only goal %s", archived, oldGoal.ID)
+	}
+
+	var live []models.WeeklyGoal
+	if err := json.Unmarshal(readZipFile(t, zr, "weekly_goals.json"), &live); err != nil {
+		t.Fatalf("failed to decode weekly_goals.json: %v", err)
+	}
+	if len(live) != 1 || live[0].ID != currentGoal.ID {
+		t.Errorf("weekly_goals.json = %+v, want only goal %s", live, currentGoal.ID)
+	}
+
+	rows, err := csv.NewReader(bytes.NewReader(readZipFile(t, zr, "weekly_goal_archives.csv"))).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to read weekly_goal_archives.csv: %v", err)
+	}
+	if len(rows) != 2 || rows[1][0] != oldGoal.ID.String() {
+		t.Errorf("weekly_goal_archives.csv = %v, want a header and goal %s", rows, oldGoal.ID)
+	}
+}
+
+func readZipFile(t *testing.T, zr *zip.Reader, name string) []byte {
+	t.Helper()
+	f, err := zr.Open(name)
+	if err != nil {
+		t.Fatalf("archive has no %s: %v", name, err)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", name, err)
+	}
+	return data
+}
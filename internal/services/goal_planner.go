@@ -0,0 +1,264 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"github.com/devlpr-nitish/leetcode-tracker-backend/pkg/leetcode"
+)
+
+// Goal generation strategies a user can choose
+const (
+	StrategyBalanced      = "balanced"
+	StrategyInterviewPrep = "interview-prep"
+	StrategyContestPrep   = "contest-prep"
+	StrategyTopicDeepDive = "topic-deep-dive"
+	StrategyMaintenance   = "maintenance"
+)
+
+var ErrUnknownStrategy = errors.New("unknown goal strategy")
+
+var weekDays = []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday"}
+
+// Difficulties in the order goals are filled
+var goalDifficulties = []string{"Easy", "Medium", "Hard"}
+
+// PlanSpec is what a planner asks goal generation to select for the week
+type PlanSpec struct {
+	Counts map[string]int // Problems per difficulty: "Easy", "Medium", "Hard"
+	// Topics whose problems are added to the candidates. With TopicsOnly, every
+	// problem comes from the first focus topic instead.
+	FocusTopics []string
+	TopicsOnly  bool
+}
+
+// GoalPlanner is one goal generation strategy. It decides how many problems of each
+// difficulty a week gets, which topics to focus on, and on which days problems go.
+type GoalPlanner interface {
+	Name() string
+	Plan(profile UserProfile) PlanSpec
+	// Distribute spreads the selected problems over the week, whose day names are listed in
+	// order in week. Day roles such as "the first day" follow that order rather than the
+	// calendar. All randomness comes from rng.
+	Distribute(problems []leetcode.APIQuestion, week []string, rng *rand.Rand) GoalPlan
+}
+
+// goalPlanners holds every available strategy by name
+var goalPlanners = map[string]GoalPlanner{
+	StrategyBalanced:      balancedPlanner{},
+	StrategyInterviewPrep: interviewPrepPlanner{},
+	StrategyContestPrep:   contestPrepPlanner{},
+	StrategyTopicDeepDive: topicDeepDivePlanner{},
+	StrategyMaintenance:   maintenancePlanner{},
+}
+
+// goalPlannerFor returns the planner for a strategy, defaulting to balanced
+func goalPlannerFor(strategy string) (GoalPlanner, error) {
+	if strategy == "" {
+		strategy = StrategyBalanced
+	}
+	planner, ok := goalPlanners[strategy]
+	if !ok {
+		return nil, ErrUnknownStrategy
+	}
+	return planner, nil
+}
+
+// balancedPlanner is the default: 8 problems with more hard ones as the user progresses,
+// an easy start on the first day of the week and mediums on the three days after it
+type balancedPlanner struct{}
+
+func (balancedPlanner) Name() string { return StrategyBalanced }
+
+func (balancedPlanner) Plan(profile UserProfile) PlanSpec {
+	ratio := DifficultyRatio{Easy: 0.2, Medium: 0.5, Hard: 0.3}
+	if profile.TotalSolved < 150 {
+		ratio = DifficultyRatio{Easy: 0.5, Medium: 0.4, Hard: 0.1}
+	} else if profile.TotalSolved < 400 {
+		ratio = DifficultyRatio{Easy: 0.3, Medium: 0.5, Hard: 0.2}
+	}
+	return PlanSpec{Counts: countsFromRatio(8, ratio), FocusTopics: firstTopics(profile.WeakTopics, 1)}
+}
+
+func (balancedPlanner) Distribute(problems []leetcode.APIQuestion, week []string, rng *rand.Rand) GoalPlan {
+	schedule := make(GoalPlan)
+	byDifficulty := bucketByDifficulty(problems)
+	easy, medium := byDifficulty["Easy"], byDifficulty["Medium"]
+
+	// First day: Easy
+	if len(easy) > 0 {
+		schedule[week[0]] = append(schedule[week[0]], newGoalProblem(easy[0]))
+		easy = easy[1:]
+	}
+
+	// Next three days: Medium Focus
+	for _, day := range week[1:4] {
+		if len(medium) > 0 {
+			schedule[day] = append(schedule[day], newGoalProblem(medium[0]))
+			medium = medium[1:]
+		}
+	}
+
+	// Fill rest
+	remaining := append(append(easy, medium...), byDifficulty["Hard"]...)
+	rng.Shuffle(len(remaining), func(i, j int) { remaining[i], remaining[j] = remaining[j], remaining[i] })
+	for i, p := range remaining {
+		day := week[i%len(week)]
+		schedule[day] = append(schedule[day], newGoalProblem(p))
+	}
+	return schedule
+}
+
+// interviewPrepPlanner favours mediums from the user's weakest topics, one or two a
+// weekday, with hards saved for the weekend
+type interviewPrepPlanner struct{}
+
+func (interviewPrepPlanner) Name() string { return StrategyInterviewPrep }
+
+func (interviewPrepPlanner) Plan(profile UserProfile) PlanSpec {
+	ratio := DifficultyRatio{Easy: 0.2, Medium: 0.6, Hard: 0.2}
+	if profile.TotalSolved < 150 {
+		ratio = DifficultyRatio{Easy: 0.3, Medium: 0.6, Hard: 0.1}
+	}
+	return PlanSpec{Counts: countsFromRatio(10, ratio), FocusTopics: firstTopics(profile.WeakTopics, 2)}
+}
+
+func (interviewPrepPlanner) Distribute(problems []leetcode.APIQuestion, week []string, rng *rand.Rand) GoalPlan {
+	return distributeByDays(problems, week, rng, map[string][]string{
+		"Easy":   {"Monday", "Tuesday"},
+		"Medium": {"Monday", "Tuesday", "Wednesday", "Thursday", "Friday"},
+		"Hard":   {"Saturday", "Sunday"},
+	})
+}
+
+// contestPrepPlanner leans on mediums and hards from the user's strong topics, building
+// up to a hard set on Saturday and leaving Sunday free for the weekly contest
+type contestPrepPlanner struct{}
+
+func (contestPrepPlanner) Name() string { return StrategyContestPrep }
+
+func (contestPrepPlanner) Plan(profile UserProfile) PlanSpec {
+	ratio := DifficultyRatio{Easy: 0.1, Medium: 0.5, Hard: 0.4}
+	if profile.TotalSolved < 400 {
+		ratio = DifficultyRatio{Easy: 0.2, Medium: 0.6, Hard: 0.2}
+	}
+	return PlanSpec{Counts: countsFromRatio(8, ratio), FocusTopics: firstTopics(profile.StrongTopics, 2)}
+}
+
+func (contestPrepPlanner) Distribute(problems []leetcode.APIQuestion, week []string, rng *rand.Rand) GoalPlan {
+	return distributeByDays(problems, week, rng, map[string][]string{
+		"Easy":   {"Monday", "Thursday"},
+		"Medium": {"Tuesday", "Wednesday", "Friday"},
+		"Hard":   {"Saturday"},
+	})
+}
+
+// topicDeepDivePlanner spends the week on the weakest topic, one problem a day from
+// easiest to hardest, starting on the first day of the week
+type topicDeepDivePlanner struct{}
+
+func (topicDeepDivePlanner) Name() string { return StrategyTopicDeepDive }
+
+func (topicDeepDivePlanner) Plan(profile UserProfile) PlanSpec {
+	ratio := DifficultyRatio{Easy: 0.3, Medium: 0.5, Hard: 0.2}
+	if profile.TotalSolved < 150 {
+		ratio = DifficultyRatio{Easy: 0.45, Medium: 0.45, Hard: 0.1}
+	}
+	topics := firstTopics(profile.WeakTopics, 1)
+	return PlanSpec{Counts: countsFromRatio(7, ratio), FocusTopics: topics, TopicsOnly: len(topics) > 0}
+}
+
+func (topicDeepDivePlanner) Distribute(problems []leetcode.APIQuestion, week []string, rng *rand.Rand) GoalPlan {
+	ordered := make([]leetcode.APIQuestion, 0, len(problems))
+	byDifficulty := bucketByDifficulty(problems)
+	for _, difficulty := range goalDifficulties {
+		ordered = append(ordered, byDifficulty[difficulty]...)
+	}
+
+	schedule := make(GoalPlan)
+	for i, p := range ordered {
+		day := week[i%len(week)]
+		schedule[day] = append(schedule[day], newGoalProblem(p))
+	}
+	return schedule
+}
+
+// maintenancePlanner is a light week: a few easy and medium problems every other day,
+// from the first day of the week to the last
+type maintenancePlanner struct{}
+
+func (maintenancePlanner) Name() string { return StrategyMaintenance }
+
+func (maintenancePlanner) Plan(profile UserProfile) PlanSpec {
+	return PlanSpec{Counts: countsFromRatio(4, DifficultyRatio{Easy: 0.5, Medium: 0.5})}
+}
+
+func (maintenancePlanner) Distribute(problems []leetcode.APIQuestion, week []string, rng *rand.Rand) GoalPlan {
+	days := []string{week[0], week[2], week[4], week[6]}
+	return distributeByDays(problems, week, rng, map[string][]string{"Easy": days, "Medium": days, "Hard": days})
+}
+
+// countsFromRatio splits total problems by ratio, giving rounding leftovers to Hard
+func countsFromRatio(total int, ratio DifficultyRatio) map[string]int {
+	easy := int(math.Round(float64(total) * ratio.Easy))
+	medium := int(math.Round(float64(total) * ratio.Medium))
+	return map[string]int{"Easy": easy, "Medium": medium, "Hard": max(total-easy-medium, 0)}
+}
+
+func firstTopics(topics []string, n int) []string {
+	if len(topics) > n {
+		return topics[:n]
+	}
+	return topics
+}
+
+func bucketByDifficulty(problems []leetcode.APIQuestion) map[string][]leetcode.APIQuestion {
+	buckets := make(map[string][]leetcode.APIQuestion)
+	for _, p := range problems {
+		switch strings.ToLower(p.Difficulty) {
+		case "easy":
+			buckets["Easy"] = append(buckets["Easy"], p)
+		case "medium":
+			buckets["Medium"] = append(buckets["Medium"], p)
+		case "hard":
+			buckets["Hard"] = append(buckets["Hard"], p)
+		}
+	}
+	return buckets
+}
+
+// distributeByDays deals each difficulty's problems round-robin over its days, starting
+// from a random one of them so repeated weeks do not always load the same day
+func distributeByDays(problems []leetcode.APIQuestion, week []string, rng *rand.Rand, days map[string][]string) GoalPlan {
+	schedule := make(GoalPlan)
+	byDifficulty := bucketByDifficulty(problems)
+	for _, difficulty := range goalDifficulties {
+		bucket, allowed := byDifficulty[difficulty], days[difficulty]
+		if len(bucket) == 0 || len(allowed) == 0 {
+			continue
+		}
+
+		// Keep the user's week order whatever order the map or caller used
+		ordered := append([]string(nil), allowed...)
+		sort.SliceStable(ordered, func(i, j int) bool { return dayIndex(week, ordered[i]) < dayIndex(week, ordered[j]) })
+
+		start := rng.Intn(len(ordered))
+		for i, p := range bucket {
+			day := ordered[(start+i)%len(ordered)]
+			schedule[day] = append(schedule[day], newGoalProblem(p))
+		}
+	}
+	return schedule
+}
+
+func dayIndex(week []string, day string) int {
+	for i, d := range week {
+		if d == day {
+			return i
+		}
+	}
+	return len(week)
+}
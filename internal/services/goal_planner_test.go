@@ -0,0 +1,131 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/devlpr-nitish/leetcode-tracker-backend/pkg/leetcode"
+)
+
+func TestPlannerPlanCounts(t *testing.T) {
+	weak := []string{"graph", "dynamic-programming", "tree"}
+	strong := []string{"array", "string", "hash-table"}
+
+	tests := []struct {
+		strategy    string
+		totalSolved int
+		want        map[string]int
+		wantTopics  []string
+		topicsOnly  bool
+	}{
+		{StrategyBalanced, 50, map[string]int{"Easy": 4, "Medium": 3, "Hard": 1}, weak[:1], false},
+		{StrategyBalanced, 200, map[string]int{"Easy": 2, "Medium": 4, "Hard": 2}, weak[:1], false},
+		{StrategyBalanced, 500, map[string]int{"Easy": 2, "Medium": 4, "Hard": 2}, weak[:1], false},
+		{StrategyInterviewPrep, 50, map[string]int{"Easy": 3, "Medium": 6, "Hard": 1}, weak[:2], false},
+		{StrategyInterviewPrep, 500, map[string]int{"Easy": 2, "Medium": 6, "Hard": 2}, weak[:2], false},
+		{StrategyContestPrep, 200, map[string]int{"Easy": 2, "Medium": 5, "Hard": 1}, strong[:2], false},
+		{StrategyContestPrep, 500, map[string]int{"Easy": 1, "Medium": 4, "Hard": 3}, strong[:2], false},
+		{StrategyTopicDeepDive, 50, map[string]int{"Easy": 3, "Medium": 3, "Hard": 1}, weak[:1], true},
+		{StrategyTopicDeepDive, 500, map[string]int{"Easy": 2, "Medium": 4, "Hard": 1}, weak[:1], true},
+		{StrategyMaintenance, 500, map[string]int{"Easy": 2, "Medium": 2, "Hard": 0}, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%d", tt.strategy, tt.totalSolved), func(t *testing.T) {
+			planner, err := goalPlannerFor(tt.strategy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			spec := planner.Plan(UserProfile{TotalSolved: tt.totalSolved, WeakTopics: weak, StrongTopics: strong})
+			if !reflect.DeepEqual(spec.Counts, tt.want) {
+				t.Errorf("Counts = %v, want %v", spec.Counts, tt.want)
+			}
+			if !reflect.DeepEqual(spec.FocusTopics, tt.wantTopics) {
+				t.Errorf("FocusTopics = %v, want %v", spec.FocusTopics, tt.wantTopics)
+			}
+			if spec.TopicsOnly != tt.topicsOnly {
+				t.Errorf("TopicsOnly = %v, want %v", spec.TopicsOnly, tt.topicsOnly)
+			}
+		})
+	}
+}
+
+func TestPlannerDistribute(t *testing.T) {
+	var problems []leetcode.APIQuestion
+	for _, difficulty := range []string{"Hard", "Easy", "Medium", "Hard", "Medium", "Easy", "Medium", "Medium"} {
+		problems = append(problems, leetcode.APIQuestion{Difficulty: difficulty, TitleSlug: fmt.Sprintf("%s-%d", difficulty, len(problems)+1)})
+	}
+
+	tests := []struct {
+		strategy string
+		firstDay time.Weekday
+		want     map[string][]string
+	}{
+		{StrategyBalanced, time.Monday, map[string][]string{
+			"Monday": {"Easy-2", "Easy-6"}, "Tuesday": {"Medium-3", "Medium-8"}, "Wednesday": {"Medium-5", "Hard-4"}, "Thursday": {"Medium-7", "Hard-1"},
+		}},
+		{StrategyBalanced, time.Sunday, map[string][]string{
+			"Sunday": {"Easy-2", "Easy-6"}, "Monday": {"Medium-3", "Medium-8"}, "Tuesday": {"Medium-5", "Hard-4"}, "Wednesday": {"Medium-7", "Hard-1"},
+		}},
+		{StrategyInterviewPrep, time.Monday, map[string][]string{
+			"Monday": {"Easy-6", "Medium-8"}, "Tuesday": {"Easy-2"}, "Wednesday": {"Medium-3"}, "Thursday": {"Medium-5"}, "Friday": {"Medium-7"},
+			"Saturday": {"Hard-4"}, "Sunday": {"Hard-1"},
+		}},
+		{StrategyInterviewPrep, time.Sunday, map[string][]string{
+			"Monday": {"Easy-6", "Medium-8"}, "Tuesday": {"Easy-2"}, "Wednesday": {"Medium-3"}, "Thursday": {"Medium-5"}, "Friday": {"Medium-7"},
+			"Saturday": {"Hard-1"}, "Sunday": {"Hard-4"},
+		}},
+		{StrategyContestPrep, time.Monday, map[string][]string{
+			"Monday": {"Easy-6"}, "Tuesday": {"Medium-3", "Medium-8"}, "Wednesday": {"Medium-5"}, "Thursday": {"Easy-2"}, "Friday": {"Medium-7"},
+			"Saturday": {"Hard-1", "Hard-4"},
+		}},
+		{StrategyTopicDeepDive, time.Monday, map[string][]string{
+			"Monday": {"Easy-2", "Hard-4"}, "Tuesday": {"Easy-6"}, "Wednesday": {"Medium-3"}, "Thursday": {"Medium-5"}, "Friday": {"Medium-7"},
+			"Saturday": {"Medium-8"}, "Sunday": {"Hard-1"},
+		}},
+		{StrategyTopicDeepDive, time.Sunday, map[string][]string{
+			"Sunday": {"Easy-2", "Hard-4"}, "Monday": {"Easy-6"}, "Tuesday": {"Medium-3"}, "Wednesday": {"Medium-5"}, "Thursday": {"Medium-7"},
+			"Friday": {"Medium-8"}, "Saturday": {"Hard-1"},
+		}},
+		{StrategyMaintenance, time.Monday, map[string][]string{
+			"Monday": {"Medium-5", "Hard-4"}, "Wednesday": {"Easy-2", "Medium-7"}, "Friday": {"Easy-6", "Medium-8"}, "Sunday": {"Medium-3", "Hard-1"},
+		}},
+		{StrategyMaintenance, time.Sunday, map[string][]string{
+			"Sunday": {"Medium-5", "Hard-4"}, "Tuesday": {"Easy-2", "Medium-7"}, "Thursday": {"Easy-6", "Medium-8"}, "Saturday": {"Medium-3", "Hard-1"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%s", tt.strategy, tt.firstDay), func(t *testing.T) {
+			planner, err := goalPlannerFor(tt.strategy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			plan := planner.Distribute(problems, weekDayOrder(tt.firstDay), rand.New(rand.NewSource(1)))
+
+			got := make(map[string][]string)
+			for day, dayProblems := range plan {
+				for _, p := range dayProblems {
+					got[day] = append(got[day], p.TitleSlug)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Distribute() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGoalPlannerForDefaultsToBalanced(t *testing.T) {
+	planner, err := goalPlannerFor("")
+	if err != nil || planner.Name() != StrategyBalanced {
+		t.Fatalf("goalPlannerFor(\"\") = %v, %v, want the balanced planner", planner, err)
+	}
+	if _, err := goalPlannerFor("speedrun"); !errors.Is(err, ErrUnknownStrategy) {
+		t.Fatalf("goalPlannerFor(\"speedrun\") error = %v, want ErrUnknownStrategy", err)
+	}
+}
@@ -0,0 +1,371 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// Estimated minutes per problem, used to fit goals into the user's daily time budget
+const (
+	easyProblemMinutes   = 15
+	mediumProblemMinutes = 30
+	hardProblemMinutes   = 60
+)
+
+const (
+	maxTargetProblems    = 50
+	maxPreferenceTopics  = 20
+	maxDailyMinutes      = 24 * 60
+	unlimitedDailyBudget = math.MaxInt
+)
+
+var ErrInvalidGoalPreferences = errors.New("invalid goal preferences")
+
+// GoalPreferences are the user's settings for weekly goal generation
+type GoalPreferences struct {
+	TargetProblems int            `json:"target_problems"` // Problems per week, 0 uses the strategy's count
+	Difficulties   []string       `json:"difficulties"`    // Empty allows all
+	IncludeTopics  []string       `json:"include_topics"`  // Tag slugs to focus on
+	ExcludeTopics  []string       `json:"exclude_topics"`  // Tag slugs never assigned
+	RestDays       []string       `json:"rest_days"`       // Days nothing is scheduled on
+	DailyMinutes   map[string]int `json:"daily_minutes"`   // Minutes available per day, missing days are unlimited
+	UpdatedAt      *time.Time     `json:"updated_at"`      // Nil until the user saves preferences
+}
+
+// GetPreferences returns the user's goal preferences, or the defaults if they never saved any
+func (s *GoalService) GetPreferences(ctx context.Context, userID uuid.UUID) (*GoalPreferences, error) {
+	stored, err := s.PreferencesRepo.GetByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil {
+		return defaultGoalPreferences(), nil
+	}
+	return goalPreferencesFromModel(stored), nil
+}
+
+// SavePreferences validates and replaces the user's goal preferences
+func (s *GoalService) SavePreferences(ctx context.Context, userID uuid.UUID, prefs GoalPreferences) (*GoalPreferences, error) {
+	if err := prefs.normalize(); err != nil {
+		return nil, err
+	}
+
+	model := &models.UserGoalPreferences{
+		UserID:         userID,
+		TargetProblems: prefs.TargetProblems,
+		Difficulties:   mustJSON(prefs.Difficulties),
+		IncludeTopics:  mustJSON(prefs.IncludeTopics),
+		ExcludeTopics:  mustJSON(prefs.ExcludeTopics),
+		RestDays:       mustJSON(prefs.RestDays),
+		DailyMinutes:   mustJSON(prefs.DailyMinutes),
+		UpdatedAt:      time.Now(),
+	}
+	if err := s.PreferencesRepo.Upsert(ctx, model); err != nil {
+		return nil, err
+	}
+	return s.GetPreferences(ctx, userID)
+}
+
+// DeletePreferences resets the user's goal preferences to the defaults
+func (s *GoalService) DeletePreferences(ctx context.Context, userID uuid.UUID) error {
+	return s.PreferencesRepo.DeleteByUser(ctx, userID)
+}
+
+func defaultGoalPreferences() *GoalPreferences {
+	return &GoalPreferences{
+		Difficulties:  []string{},
+		IncludeTopics: []string{},
+		ExcludeTopics: []string{},
+		RestDays:      []string{},
+		DailyMinutes:  map[string]int{},
+	}
+}
+
+func goalPreferencesFromModel(m *models.UserGoalPreferences) *GoalPreferences {
+	prefs := defaultGoalPreferences()
+	prefs.TargetProblems = m.TargetProblems
+	_ = json.Unmarshal(m.Difficulties, &prefs.Difficulties)
+	_ = json.Unmarshal(m.IncludeTopics, &prefs.IncludeTopics)
+	_ = json.Unmarshal(m.ExcludeTopics, &prefs.ExcludeTopics)
+	_ = json.Unmarshal(m.RestDays, &prefs.RestDays)
+	_ = json.Unmarshal(m.DailyMinutes, &prefs.DailyMinutes)
+	updatedAt := m.UpdatedAt
+	prefs.UpdatedAt = &updatedAt
+	return prefs
+}
+
+func mustJSON(v interface{}) datatypes.JSON {
+	encoded, _ := json.Marshal(v)
+	return datatypes.JSON(encoded)
+}
+
+// normalize validates the preferences and puts names in their canonical form
+func (p *GoalPreferences) normalize() error {
+	if p.TargetProblems < 0 || p.TargetProblems > maxTargetProblems {
+		return fmt.Errorf("%w: target_problems must be between 0 and %d", ErrInvalidGoalPreferences, maxTargetProblems)
+	}
+
+	difficulties := []string{}
+	for _, raw := range p.Difficulties {
+		difficulty := canonicalDifficulty(raw)
+		if difficulty == "" {
+			return fmt.Errorf("%w: unknown difficulty %q", ErrInvalidGoalPreferences, raw)
+		}
+		if !containsString(difficulties, difficulty) {
+			difficulties = append(difficulties, difficulty)
+		}
+	}
+	p.Difficulties = difficulties
+
+	var err error
+	if p.IncludeTopics, err = normalizeTopics(p.IncludeTopics); err != nil {
+		return err
+	}
+	if p.ExcludeTopics, err = normalizeTopics(p.ExcludeTopics); err != nil {
+		return err
+	}
+	for _, topic := range p.IncludeTopics {
+		if containsString(p.ExcludeTopics, topic) {
+			return fmt.Errorf("%w: topic %q is both included and excluded", ErrInvalidGoalPreferences, topic)
+		}
+	}
+
+	restDays := []string{}
+	for _, raw := range p.RestDays {
+		day := canonicalDay(raw)
+		if day == "" {
+			return fmt.Errorf("%w: unknown day %q", ErrInvalidGoalPreferences, raw)
+		}
+		if !containsString(restDays, day) {
+			restDays = append(restDays, day)
+		}
+	}
+	if len(restDays) == len(weekDays) {
+		return fmt.Errorf("%w: at least one day must not be a rest day", ErrInvalidGoalPreferences)
+	}
+	p.RestDays = restDays
+
+	minutes := map[string]int{}
+	for raw, value := range p.DailyMinutes {
+		day := canonicalDay(raw)
+		if day == "" {
+			return fmt.Errorf("%w: unknown day %q", ErrInvalidGoalPreferences, raw)
+		}
+		if value < 1 || value > maxDailyMinutes {
+			return fmt.Errorf("%w: daily_minutes must be between 1 and %d", ErrInvalidGoalPreferences, maxDailyMinutes)
+		}
+		minutes[day] = value
+	}
+	p.DailyMinutes = minutes
+	return nil
+}
+
+func normalizeTopics(topics []string) ([]string, error) {
+	if len(topics) > maxPreferenceTopics {
+		return nil, fmt.Errorf("%w: at most %d topics can be listed", ErrInvalidGoalPreferences, maxPreferenceTopics)
+	}
+	normalized := []string{}
+	for _, raw := range topics {
+		topic := strings.ToLower(strings.TrimSpace(raw))
+		if topic == "" {
+			continue
+		}
+		if !containsString(normalized, topic) {
+			normalized = append(normalized, topic)
+		}
+	}
+	return normalized, nil
+}
+
+func canonicalDifficulty(raw string) string {
+	for _, difficulty := range goalDifficulties {
+		if strings.EqualFold(strings.TrimSpace(raw), difficulty) {
+			return difficulty
+		}
+	}
+	return ""
+}
+
+func canonicalDay(raw string) string {
+	for _, day := range weekDays {
+		if strings.EqualFold(strings.TrimSpace(raw), day) {
+			return day
+		}
+	}
+	return ""
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func problemMinutes(difficulty string) int {
+	switch difficulty {
+	case "Easy":
+		return easyProblemMinutes
+	case "Hard":
+		return hardProblemMinutes
+	default:
+		return mediumProblemMinutes
+	}
+}
+
+// budget returns the minutes available on day: 0 on rest days, unlimitedDailyBudget when not set
+func (p *GoalPreferences) budget(day string) int {
+	if containsString(p.RestDays, day) {
+		return 0
+	}
+	if minutes, ok := p.DailyMinutes[day]; ok {
+		return minutes
+	}
+	return unlimitedDailyBudget
+}
+
+// averageMinutes is the mean daily budget over the days that have one, 0 when none do
+func (p *GoalPreferences) averageMinutes() int {
+	if len(p.DailyMinutes) == 0 {
+		return 0
+	}
+	total := 0
+	for _, minutes := range p.DailyMinutes {
+		total += minutes
+	}
+	return total / len(p.DailyMinutes)
+}
+
+// adjustSpec applies the weekly target, allowed difficulties and topic choices to a planner's spec
+func (p *GoalPreferences) adjustSpec(spec PlanSpec) PlanSpec {
+	counts := make(map[string]int, len(goalDifficulties))
+	total := 0
+	for _, difficulty := range goalDifficulties {
+		counts[difficulty] = spec.Counts[difficulty]
+		total += spec.Counts[difficulty]
+	}
+
+	// Scale to the target, keeping the planner's mix
+	if p.TargetProblems > 0 && total > 0 {
+		counts = countsFromRatio(p.TargetProblems, DifficultyRatio{
+			Easy:   float64(counts["Easy"]) / float64(total),
+			Medium: float64(counts["Medium"]) / float64(total),
+			Hard:   float64(counts["Hard"]) / float64(total),
+		})
+	}
+
+	// Hard problems only go on days with time for one. Without such a day they become mediums.
+	hardDay := false
+	for _, day := range weekDays {
+		if p.budget(day) >= hardProblemMinutes {
+			hardDay = true
+		}
+	}
+	if !hardDay {
+		counts["Medium"] += counts["Hard"]
+		counts["Hard"] = 0
+	}
+
+	// Hand the problems of disallowed difficulties to the allowed ones in turn
+	if len(p.Difficulties) > 0 {
+		moved := 0
+		for _, difficulty := range goalDifficulties {
+			if !containsString(p.Difficulties, difficulty) {
+				moved += counts[difficulty]
+				counts[difficulty] = 0
+			}
+		}
+		for i := 0; i < moved; i++ {
+			counts[p.Difficulties[i%len(p.Difficulties)]]++
+		}
+	}
+	spec.Counts = counts
+
+	focus := append([]string{}, p.IncludeTopics...)
+	for _, topic := range spec.FocusTopics {
+		if !containsString(focus, topic) && !containsString(p.ExcludeTopics, topic) {
+			focus = append(focus, topic)
+		}
+	}
+	spec.FocusTopics = focus
+	if len(focus) == 0 {
+		spec.TopicsOnly = false
+	}
+	return spec
+}
+
+// fitSchedule moves problems off rest days and days without time for them. Problems stay
+// on their day when they fit; the rest go to the least loaded open day, hard problems only
+// to days long enough for one when there is any. Days are visited in the order of week.
+// Problems in fixed, such as ones already completed, keep their day but use up its time.
+func (p *GoalPreferences) fitSchedule(fixed, plan GoalPlan, week []string) GoalPlan {
+	fitted := make(GoalPlan)
+	used := make(map[string]int)
+	for day, problems := range fixed {
+		fitted[day] = append(fitted[day], problems...)
+		for _, problem := range problems {
+			used[day] += problemMinutes(problem.Difficulty)
+		}
+	}
+
+	if len(p.RestDays) == 0 && len(p.DailyMinutes) == 0 {
+		for day, problems := range plan {
+			fitted[day] = append(fitted[day], problems...)
+		}
+		return fitted
+	}
+
+	fits := func(day string, problem models.GoalProblem) bool {
+		budget := p.budget(day)
+		if budget == 0 || (problem.Difficulty == "Hard" && budget < hardProblemMinutes) {
+			return false
+		}
+		return budget == unlimitedDailyBudget || used[day]+problemMinutes(problem.Difficulty) <= budget
+	}
+
+	var displaced []models.GoalProblem
+	for _, day := range week {
+		for _, problem := range plan[day] {
+			if fits(day, problem) {
+				fitted[day] = append(fitted[day], problem)
+				used[day] += problemMinutes(problem.Difficulty)
+			} else {
+				displaced = append(displaced, problem)
+			}
+		}
+	}
+
+	for _, problem := range displaced {
+		// Prefer a day it fits on, then any day long enough for its difficulty, then any open day
+		best, bestRank := "", -1
+		for _, day := range week {
+			budget := p.budget(day)
+			if budget == 0 {
+				continue
+			}
+			rank := 0
+			if fits(day, problem) {
+				rank = 2
+			} else if problem.Difficulty != "Hard" || budget >= hardProblemMinutes {
+				rank = 1
+			}
+			if rank > bestRank || (rank == bestRank && used[day] < used[best]) {
+				best, bestRank = day, rank
+			}
+		}
+		fitted[best] = append(fitted[best], problem)
+		used[best] += problemMinutes(problem.Difficulty)
+	}
+	return fitted
+}
@@ -0,0 +1,224 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/models"
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/repository"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// finalizeBatchSize caps how many ended goals are decided per run
+const finalizeBatchSize = 200
+
+// GoalPlan maps week days ("Monday") to the problems planned for them. It is the working
+// form of a goal's models.GoalProblemSet.
+type GoalPlan map[string][]models.GoalProblem
+
+// parseGoalPlan reads SelectedProblems in any stored version
+func parseGoalPlan(raw datatypes.JSON) (GoalPlan, error) {
+	set, err := models.ParseGoalProblemSet(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := GoalPlan{}
+	for _, problem := range set.Problems {
+		plan[problem.Day] = append(plan[problem.Day], problem)
+	}
+	return plan, nil
+}
+
+// counts returns how many of the plan's problems are completed, out of how many
+func (p GoalPlan) counts() (completed, total int) {
+	for _, problems := range p {
+		for _, problem := range problems {
+			total++
+			if problem.Completed {
+				completed++
+			}
+		}
+	}
+	return completed, total
+}
+
+// HandleSync is registered as a UserService sync listener to keep goal progress current
+func (s *GoalService) HandleSync(ctx context.Context, result *SyncResult) error {
+	return s.UpdateProgress(ctx, result.User.ID)
+}
+
+// UpdateProgress recomputes completion of the user's pending goals from their accepted
+// submissions. A goal that fails does not stop the others; all errors are returned together.
+func (s *GoalService) UpdateProgress(ctx context.Context, userID uuid.UUID) error {
+	goals, err := s.GoalRepo.ListPendingByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for i := range goals {
+		if err := s.refreshProgress(ctx, &goals[i]); err != nil {
+			errs = append(errs, fmt.Errorf("goal %s: %w", goals[i].ID, err))
+			continue
+		}
+		if err := s.GoalRepo.Update(ctx, &goals[i]); err != nil {
+			errs = append(errs, fmt.Errorf("goal %s: %w", goals[i].ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// FinalizeEndedGoals decides goals whose week is over: COMPLETED when every problem was
+// solved during the week, FAILED otherwise. The outcome is also written to the activity log.
+// A goal whose progress cannot be recomputed is decided on its last recorded progress, so it
+// is not fetched again on every run.
+func (s *GoalService) FinalizeEndedGoals(ctx context.Context) error {
+	// Weeks end at midnight in their own zone, at most a day away from midnight UTC, so the
+	// date cutoff is a day late and each goal's end is checked exactly
+	ended := s.Now().Add(-s.FinalizeGrace)
+	cutoff := ended.AddDate(0, 0, -6)
+	goals, err := s.GoalRepo.ListPendingStartedBefore(ctx, cutoff, finalizeBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for i := range goals {
+		goal := &goals[i]
+		_, weekEnd := weekBounds(goal.WeekStartDate, loadLocation(goal.WeekTimezone))
+		if weekEnd.After(ended) {
+			continue
+		}
+		if err := s.refreshProgress(ctx, goal); err != nil {
+			log.Printf("Failed to compute final progress of goal %s, using its last recorded progress: %v", goal.ID, err)
+		}
+
+		now := s.Now()
+		goal.Status = models.GoalStatusFailed
+		activityType := models.ActivityTypeGoalFailed
+		if goal.TotalProblems > 0 && goal.CompletedProblems == goal.TotalProblems {
+			goal.Status = models.GoalStatusCompleted
+			activityType = models.ActivityTypeGoalCompleted
+		}
+		goal.FinalizedAt = &now
+
+		if err := s.GoalRepo.Update(ctx, goal); err != nil {
+			log.Printf("Failed to finalize goal %s: %v", goal.ID, err)
+			continue
+		}
+
+		outcome := models.ActivityLog{
+			UserID:       goal.UserID,
+			ActivityType: activityType,
+			ReferenceID:  goal.ID.String(),
+			Timestamp:    weekEnd,
+		}
+		if err := s.ActivityRepo.CreateIgnoringDuplicates(ctx, []models.ActivityLog{outcome}); err != nil {
+			log.Printf("Failed to record outcome of goal %s: %v", goal.ID, err)
+		}
+	}
+	return nil
+}
+
+// refreshProgress marks the goal's problems that were accepted during the goal week, from
+// midnight to midnight in the zone the goal was planned in, and updates its counters. It
+// does not save the goal.
+func (s *GoalService) refreshProgress(ctx context.Context, goal *models.WeeklyGoal) error {
+	plan, err := parseGoalPlan(goal.SelectedProblems)
+	if err != nil {
+		return err
+	}
+
+	from, to := weekBounds(goal.WeekStartDate, loadLocation(goal.WeekTimezone))
+	logs, err := s.ActivityRepo.ListByType(ctx, goal.UserID, models.ActivityTypeProblemSolved, from, to)
+	if err != nil {
+		return err
+	}
+	// Logs are oldest first, so the first entry per slug is when it was first accepted that week
+	solvedAt := make(map[string]time.Time)
+	for _, entry := range logs {
+		if _, ok := solvedAt[entry.ReferenceID]; !ok {
+			solvedAt[entry.ReferenceID] = entry.Timestamp
+		}
+	}
+
+	// Legacy problems only have a title, resolve it through the solved problems we know
+	var slugByTitle map[string]string
+	for day, problems := range plan {
+		for i := range problems {
+			problem := &problems[i]
+			if problem.Completed {
+				continue
+			}
+
+			slug := problem.TitleSlug
+			if slug == "" {
+				if slugByTitle == nil {
+					if slugByTitle, err = SolvedSlugsByTitle(ctx, s.SolvedRepo, goal.UserID); err != nil {
+						return err
+					}
+				}
+				slug = slugByTitle[strings.ToLower(problem.Title)]
+			}
+
+			if at, ok := solvedAt[slug]; ok && slug != "" {
+				problem.Completed = true
+				problem.CompletedAt = &at
+			}
+		}
+		plan[day] = problems
+	}
+
+	return setGoalPlan(goal, plan)
+}
+
+// setGoalPlan stores plan as the goal's selected problems, in the current schema version
+// and ordered by the goal's week, and updates its counters
+func setGoalPlan(goal *models.WeeklyGoal, plan GoalPlan) error {
+	var problems []models.GoalProblem
+	for day, dayProblems := range plan {
+		for _, problem := range dayProblems {
+			problem.Day = day
+			problems = append(problems, problem)
+		}
+	}
+	// Map order is random, so sort first to keep unknown day names in a stable order
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].Day < problems[j].Day })
+
+	encoded, err := json.Marshal(models.NewGoalProblemSet(problems, goal.WeekStartDate))
+	if err != nil {
+		return err
+	}
+
+	completed, total := plan.counts()
+	goal.SelectedProblems = datatypes.JSON(encoded)
+	goal.CompletedProblems = completed
+	goal.TotalProblems = total
+	goal.CompletionPercent = 0
+	if total > 0 {
+		goal.CompletionPercent = float64(completed) / float64(total) * 100
+	}
+	return nil
+}
+
+// SolvedSlugsByTitle maps the lowercased titles of the user's solved problems to their slugs,
+// which is how legacy goal problems stored by title are matched
+func SolvedSlugsByTitle(ctx context.Context, solvedRepo repository.SolvedProblemRepository, userID uuid.UUID) (map[string]string, error) {
+	solved, err := solvedRepo.ListAllByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	slugs := make(map[string]string, len(solved))
+	for _, p := range solved {
+		slugs[strings.ToLower(p.Title)] = p.TitleSlug
+	}
+	return slugs, nil
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// GoalResponse is a weekly goal as returned by the API, live or archived
+type GoalResponse struct {
+	ID                  uuid.UUID            `json:"id"`
+	WeekStartDate       string               `json:"week_start_date"` // YYYY-MM-DD in WeekTimezone
+	WeekTimezone        string               `json:"week_timezone"`
+	WeekStart           time.Time            `json:"week_start"` // Instant the week starts
+	WeekEnd             time.Time            `json:"week_end"`   // Instant the week ends, exclusive
+	Days                []string             `json:"days"`       // Day names in the week's order
+	GoalType            string               `json:"goal_type"`
+	Strategy            string               `json:"strategy"`
+	Status              string               `json:"status"`
+	DifficultyBreakdown map[string]int       `json:"difficulty_breakdown"` // {"easy": 3, "medium": 4, "hard": 1}
+	FocusTopics         []string             `json:"focus_topics"`         // Tag slugs
+	Problems            []models.GoalProblem `json:"problems"`             // By day, in the order of Days
+	CompletionPercent   float64              `json:"completion_percent"`
+	CompletedProblems   int                  `json:"completed_problems"`
+	TotalProblems       int                  `json:"total_problems"`
+	FinalizedAt         *time.Time           `json:"finalized_at"`
+	ArchivedAt          *time.Time           `json:"archived_at,omitempty"`
+	CreatedAt           time.Time            `json:"created_at"`
+	UpdatedAt           time.Time            `json:"updated_at"`
+}
+
+func newGoalResponse(goal *models.WeeklyGoal) *GoalResponse {
+	resp := buildGoalResponse(goal.ID, goal.WeekStartDate, goal.WeekTimezone, goal.DifficultyBreakdown, goal.SelectedProblems, goal.FocusTopics)
+	resp.ID = goal.ID
+	resp.GoalType = goal.GoalType
+	resp.Strategy = goal.Strategy
+	resp.Status = goal.Status
+	resp.CompletionPercent = goal.CompletionPercent
+	resp.CompletedProblems = goal.CompletedProblems
+	resp.TotalProblems = goal.TotalProblems
+	resp.FinalizedAt = goal.FinalizedAt
+	resp.CreatedAt = goal.CreatedAt
+	resp.UpdatedAt = goal.UpdatedAt
+	return resp
+}
+
+func newArchivedGoalResponse(goal *models.WeeklyGoalArchive) *GoalResponse {
+	resp := buildGoalResponse(goal.ID, goal.WeekStartDate, goal.WeekTimezone, goal.DifficultyBreakdown, goal.SelectedProblems, goal.FocusTopics)
+	archivedAt := goal.ArchivedAt
+	resp.ID = goal.ID
+	resp.GoalType = goal.GoalType
+	resp.Strategy = goal.Strategy
+	resp.Status = goal.Status
+	resp.CompletionPercent = goal.CompletionPercent
+	resp.CompletedProblems = goal.CompletedProblems
+	resp.TotalProblems = goal.TotalProblems
+	resp.FinalizedAt = goal.FinalizedAt
+	resp.ArchivedAt = &archivedAt
+	resp.CreatedAt = goal.CreatedAt
+	resp.UpdatedAt = goal.UpdatedAt
+	return resp
+}
+
+// buildGoalResponse decodes the JSON columns shared by live and archived goals. A problem set
+// that cannot be decoded is logged and returned empty, so one bad row does not hide the others.
+func buildGoalResponse(id uuid.UUID, weekStart time.Time, timezone string, breakdown, selected, focus datatypes.JSON) *GoalResponse {
+	set, err := models.ParseGoalProblemSet(selected)
+	if err != nil {
+		log.Printf("Failed to decode problems of goal %s: %v", id, err)
+		set = models.GoalProblemSet{Version: models.GoalProblemSetVersion, Problems: []models.GoalProblem{}}
+	}
+	// Legacy sets come back ordered from Monday
+	set = models.NewGoalProblemSet(set.Problems, weekStart)
+
+	from, to := weekBounds(weekStart, loadLocation(timezone))
+	resp := &GoalResponse{
+		WeekStartDate:       weekStart.Format(dateLayout),
+		WeekTimezone:        timezone,
+		WeekStart:           from,
+		WeekEnd:             to,
+		Days:                weekDayOrder(weekStart.Weekday()),
+		DifficultyBreakdown: map[string]int{},
+		FocusTopics:         []string{},
+		Problems:            set.Problems,
+	}
+	if len(breakdown) > 0 {
+		_ = json.Unmarshal(breakdown, &resp.DifficultyBreakdown)
+	}
+	if len(focus) > 0 {
+		_ = json.Unmarshal(focus, &resp.FocusTopics)
+	}
+	// A JSON null decodes to nil
+	if resp.DifficultyBreakdown == nil {
+		resp.DifficultyBreakdown = map[string]int{}
+	}
+	if resp.FocusTopics == nil {
+		resp.FocusTopics = []string{}
+	}
+	return resp
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/devlpr-nitish/leetcode-tracker-backend/pkg/leetcode"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+const (
+	// candidatePoolFactor is how many fresh candidates we try to collect per needed problem
+	candidatePoolFactor = 3
+	// minCandidatePage keeps pages reasonably large even when only one problem is needed
+	minCandidatePage = 20
+	// maxCandidateFetches bounds the upstream calls made for one category
+	maxCandidateFetches = 6
+	// maxRandomSkip spreads the starting page so users do not all get the same problems
+	maxRandomSkip = 100
+)
+
+// CategoryShortfall reports a category that did not have enough fresh problems
+type CategoryShortfall struct {
+	Category  string `json:"category"` // Difficulty, e.g. "Hard"
+	Requested int    `json:"requested"`
+	Selected  int    `json:"selected"`
+}
+
+// problemExclusions holds problems that must not be assigned again, and topics the user
+// does not want. Older goals only stored titles, so both slugs and titles are matched.
+type problemExclusions struct {
+	slugs  map[string]bool
+	titles map[string]bool
+	topics map[string]bool
+}
+
+func newProblemExclusions() *problemExclusions {
+	return &problemExclusions{slugs: map[string]bool{}, titles: map[string]bool{}, topics: map[string]bool{}}
+}
+
+func (e *problemExclusions) addTopics(slugs []string) {
+	for _, slug := range slugs {
+		e.topics[slug] = true
+	}
+}
+
+func (e *problemExclusions) add(slug, title string) {
+	if slug != "" {
+		e.slugs[slug] = true
+	}
+	if title != "" {
+		e.titles[strings.ToLower(title)] = true
+	}
+}
+
+func (e *problemExclusions) has(q leetcode.APIQuestion) bool {
+	if e.slugs[q.TitleSlug] || e.titles[strings.ToLower(q.Title)] {
+		return true
+	}
+	for _, tag := range q.TopicTags {
+		if e.topics[tag.Slug] {
+			return true
+		}
+	}
+	return false
+}
+
+// loadExclusions collects the user's solved problems and everything assigned since the
+// start of the week RecentWeeks before weekStart (including weekStart's own goals)
+func (s *GoalService) loadExclusions(ctx context.Context, userID uuid.UUID, weekStart time.Time) (*problemExclusions, error) {
+	exclusions := newProblemExclusions()
+
+	solved, err := s.SolvedRepo.ListAllByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range solved {
+		exclusions.add(p.TitleSlug, p.Title)
+	}
+
+	// Recent weeks may already have been moved to the archive by retention cleanup
+	since := weekStart.AddDate(0, 0, -7*s.RecentWeeks)
+	recent, err := s.GoalRepo.ListSince(ctx, userID, since)
+	if err != nil {
+		return nil, err
+	}
+	archived, err := s.GoalRepo.ListArchivedSince(ctx, userID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	plans := make([]datatypes.JSON, 0, len(recent)+len(archived))
+	for _, goal := range recent {
+		plans = append(plans, goal.SelectedProblems)
+	}
+	for _, goal := range archived {
+		plans = append(plans, goal.SelectedProblems)
+	}
+	for _, raw := range plans {
+		plan, err := parseGoalPlan(raw)
+		if err != nil {
+			continue
+		}
+		for _, problems := range plan {
+			for _, p := range problems {
+				exclusions.add(p.TitleSlug, p.Title)
+			}
+		}
+	}
+
+	return exclusions, nil
+}
+
+// fetchFreshCandidates pages through the problem list until it has enough problems that are
+// not excluded, wrapping around once from a random starting page. It returns what it found
+// even when that is fewer than count.
+func (s *GoalService) fetchFreshCandidates(ctx context.Context, difficulty string, topics []string, count int, exclusions *problemExclusions, rng *rand.Rand) ([]leetcode.APIQuestion, error) {
+	if count <= 0 {
+		return nil, nil
+	}
+
+	want := count * candidatePoolFactor
+	pageSize := want
+	if pageSize < minCandidatePage {
+		pageSize = minCandidatePage
+	}
+
+	seen := map[string]bool{}
+	fresh := []leetcode.APIQuestion{}
+	start := rng.Intn(maxRandomSkip)
+	skip := start
+	wrapped := start == 0
+
+	for fetches := 0; fetches < maxCandidateFetches && len(fresh) < want; fetches++ {
+		page, err := s.ProblemRepo.GetProblemsPage(ctx, difficulty, topics, pageSize, skip)
+		if err != nil {
+			if len(fresh) > 0 {
+				break // Work with what we have rather than failing the whole generation
+			}
+			return nil, err
+		}
+
+		for _, q := range page {
+			if seen[q.QuestionFrontendId] || exclusions.has(q) {
+				continue
+			}
+			seen[q.QuestionFrontendId] = true
+			fresh = append(fresh, q)
+		}
+
+		if len(page) < pageSize {
+			// End of the list: start over from the top once, then give up
+			if wrapped {
+				break
+			}
+			wrapped = true
+			skip = 0
+			continue
+		}
+		skip += pageSize
+		if wrapped && skip >= start {
+			break // Came back around to where we started
+		}
+	}
+
+	return fresh, nil
+}
@@ -3,12 +3,14 @@ package services
 import (
 	"context"
 	"encoding/json"
-	"math"
+	"errors"
+	"log"
 	"math/rand"
 	"sort"
 	"strings"
 	"time"
 
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/config"
 	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/models"
 	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/repository"
 	"github.com/devlpr-nitish/leetcode-tracker-backend/pkg/leetcode"
@@ -17,19 +19,90 @@ import (
 )
 
 type GoalService struct {
-	UserRepo    repository.UserRepository
-	GoalRepo    repository.GoalRepository
-	ProblemRepo repository.ProblemRepository
+	UserRepo        repository.UserRepository
+	GoalRepo        repository.GoalRepository
+	ProblemRepo     repository.ProblemRepository
+	SolvedRepo      repository.SolvedProblemRepository
+	ActivityRepo    repository.ActivityRepository
+	Topics          *TopicService
+	PreferencesRepo repository.GoalPreferencesRepository
+	RecentWeeks     int           // Problems assigned in this many previous weeks are not assigned again
+	FinalizeGrace   time.Duration // Wait after a week ends before marking its goals COMPLETED or FAILED
+
+	// Retention window of live goals, in weeks around the current one
+	RetentionPastWeeks int
+	RetentionNextWeeks int
+	ArchiveEnabled     bool
+
+	// NewRand returns the random source for one generation. Replace it with a fixed seed
+	// to make generation reproducible.
+	NewRand func() *rand.Rand
+	// Now is the clock week boundaries are computed from
+	Now func() time.Time
 }
 
-func NewGoalService(userRepo repository.UserRepository, goalRepo repository.GoalRepository, problemRepo repository.ProblemRepository) *GoalService {
+const (
+	defaultArchivePageSize = 20
+	maxArchivePageSize     = 100
+)
+
+func NewGoalService(userRepo repository.UserRepository, goalRepo repository.GoalRepository, problemRepo repository.ProblemRepository, solvedRepo repository.SolvedProblemRepository, activityRepo repository.ActivityRepository, topicService *TopicService, preferencesRepo repository.GoalPreferencesRepository, cfg *config.Config) *GoalService {
 	return &GoalService{
-		UserRepo:    userRepo,
-		GoalRepo:    goalRepo,
-		ProblemRepo: problemRepo,
+		UserRepo:        userRepo,
+		GoalRepo:        goalRepo,
+		ProblemRepo:     problemRepo,
+		SolvedRepo:      solvedRepo,
+		ActivityRepo:    activityRepo,
+		Topics:          topicService,
+		PreferencesRepo: preferencesRepo,
+		RecentWeeks:     cfg.GoalExcludeRecentWeeks,
+		FinalizeGrace:   cfg.GoalFinalizeGrace,
+
+		RetentionPastWeeks: cfg.GoalRetentionPastWeeks,
+		RetentionNextWeeks: cfg.GoalRetentionNextWeeks,
+		ArchiveEnabled:     cfg.GoalArchiveEnabled,
+
+		NewRand: func() *rand.Rand { return rand.New(rand.NewSource(time.Now().UnixNano())) },
+		Now:     time.Now,
 	}
 }
 
+var (
+	ErrInvalidGoalWeek  = errors.New("week must be current or next")
+	ErrGoalWeekRetained = errors.New("goals for that week would be removed by retention cleanup")
+	ErrGoalFinalized    = errors.New("goal week has already been finalized")
+)
+
+// Weeks goals can be generated for
+const (
+	GoalWeekCurrent = "current"
+	GoalWeekNext    = "next"
+)
+
+// GenerationOptions selects the week GenerateWeeklyGoals plans, whether it replaces an existing
+// goal, and the strategy to use instead of the user's own
+type GenerationOptions struct {
+	Week       string `json:"week,omitempty"` // GoalWeekCurrent (default) or GoalWeekNext
+	Regenerate bool   `json:"regenerate,omitempty"`
+	Strategy   string `json:"strategy,omitempty"`
+}
+
+// GenerationResult is the goal created by GenerateWeeklyGoals, plus the categories
+// that did not have enough problems the user has not solved or seen recently.
+// When the week already had a goal and regeneration was not requested, Goal is
+// that goal and Created is false.
+type GenerationResult struct {
+	Goal        *GoalResponse       `json:"goal"`
+	Created     bool                `json:"created"`
+	Regenerated bool                `json:"regenerated"`
+	Exhausted   []CategoryShortfall `json:"exhausted"`
+}
+
+func (r *GenerationResult) withGoal(goal *models.WeeklyGoal) *GenerationResult {
+	r.Goal = newGoalResponse(goal)
+	return r
+}
+
 // UserProfile definitions for the algorithm
 type UserProfile struct {
 	TotalSolved    int
@@ -44,112 +117,326 @@ type UserProfile struct {
 	}
 }
 
+// DifficultyRatio is the share of a week's problems per difficulty
 type DifficultyRatio struct {
 	Easy   float64
 	Medium float64
 	Hard   float64
 }
 
-// Goals Cleanup Logic
-func (s *GoalService) CleanupOldGoals(ctx context.Context, userID uuid.UUID) error {
-	// Keep: Past 1 week, Current week, Next 1 week.
-	// Current week start
-	// now := getWeekStart()
-	// pastLimit := now.AddDate(0, 0, -7)
-	// futureLimit := now.AddDate(0, 0, 14) // Allow generating next week too, so keep valid range
-
-	// Implementation depends on repo, but for now we can assume repo handles specific logic closer to DB
-	// OR we fetch all and delete. Better to add a DeleteBefore method in repo.
-	// For MVP without changing repo interface too much, let's just leave a placeholder or implemented if repo allows.
-	// See Task: "store only weekly goals for user, like one week past and current week and one week next only"
+// CleanupOldGoals applies the retention window to one user's goals: only RetentionPastWeeks
+// before the current week, the current week and RetentionNextWeeks after it stay live
+func (s *GoalService) CleanupOldGoals(ctx context.Context, user *models.User) error {
+	keepFrom, keepUntil := s.retentionWindow(s.currentWeekStart(user))
+	_, err := s.GoalRepo.ArchiveAndDelete(ctx, &user.ID, keepFrom, keepUntil, s.ArchiveEnabled)
+	return err
+}
 
-	// We will implement a repo method call if possible, or raw SQL exec if repo is limited.
+// CleanupAllGoals applies the retention window to every user's goals. Users' weeks start on
+// different dates depending on their timezone and first day of the week, always within 7 days
+// of the UTC Monday, so the window is widened by a week on each side; generation applies the
+// exact window per user.
+func (s *GoalService) CleanupAllGoals(ctx context.Context) error {
+	keepFrom, keepUntil := s.retentionWindow(weekStartDate(s.Now(), time.UTC, time.Monday))
+	keepFrom, keepUntil = keepFrom.AddDate(0, 0, -7), keepUntil.AddDate(0, 0, 7)
+	removed, err := s.GoalRepo.ArchiveAndDelete(ctx, nil, keepFrom, keepUntil, s.ArchiveEnabled)
+	if err != nil {
+		return err
+	}
+	if removed > 0 {
+		log.Printf("Goal cleanup removed %d goals outside the retention window", removed)
+	}
 	return nil
 }
 
-// GenerateWeeklyGoals is the core logic for creating new personalized goals
-func (s *GoalService) GenerateWeeklyGoals(ctx context.Context, userID uuid.UUID) error {
+// ArchivePage is one page of a user's archived goals
+type ArchivePage struct {
+	Goals []GoalResponse `json:"goals"`
+	Total int64          `json:"total"`
+	Page  int            `json:"page"`
+	Limit int            `json:"limit"`
+}
+
+// ListArchivedGoals returns goals moved out of the live window, most recent week first. Pages start at 1.
+func (s *GoalService) ListArchivedGoals(ctx context.Context, userID uuid.UUID, page, limit int) (*ArchivePage, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = defaultArchivePageSize
+	}
+	if limit > maxArchivePageSize {
+		limit = maxArchivePageSize
+	}
+
+	goals, total, err := s.GoalRepo.ListArchived(ctx, userID, (page-1)*limit, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ArchivePage{Goals: make([]GoalResponse, 0, len(goals)), Total: total, Page: page, Limit: limit}
+	for i := range goals {
+		result.Goals = append(result.Goals, *newArchivedGoalResponse(&goals[i]))
+	}
+	return result, nil
+}
+
+func (s *GoalService) retentionWindow(weekStart time.Time) (keepFrom, keepUntil time.Time) {
+	return weekStart.AddDate(0, 0, -7*s.RetentionPastWeeks), weekStart.AddDate(0, 0, 7*s.RetentionNextWeeks)
+}
+
+// ValidateGenerationOptions reports whether GenerateWeeklyGoals can plan the requested week
+func (s *GoalService) ValidateGenerationOptions(opts GenerationOptions) error {
+	if opts.Strategy != "" {
+		if _, err := goalPlannerFor(opts.Strategy); err != nil {
+			return err
+		}
+	}
+	_, err := s.generationWeekOffset(opts)
+	return err
+}
+
+// generationWeekOffset returns how many weeks after the current one opts asks to plan
+func (s *GoalService) generationWeekOffset(opts GenerationOptions) (int, error) {
+	switch opts.Week {
+	case "", GoalWeekCurrent:
+		return 0, nil
+	case GoalWeekNext:
+		if s.RetentionNextWeeks < 1 {
+			return 0, ErrGoalWeekRetained
+		}
+		return 1, nil
+	default:
+		return 0, ErrInvalidGoalWeek
+	}
+}
+
+// GenerateWeeklyGoals is the core logic for creating new personalized goals.
+// It is idempotent per user and week: an existing goal is returned as is unless
+// opts.Regenerate is set, in which case its problems are replaced but the ones
+// already completed are kept.
+func (s *GoalService) GenerateWeeklyGoals(ctx context.Context, userID uuid.UUID, opts GenerationOptions) (*GenerationResult, error) {
+	weekOffset, err := s.generationWeekOffset(opts)
+	if err != nil {
+		return nil, err
+	}
+
 	user, err := s.UserRepo.GetByID(ctx, userID)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
 	}
 
-	// 1. Feature Extraction
-	profile := s.buildUserProfile(user)
+	// Weeks are dates, so adding days cannot be thrown off by DST
+	weekStart := s.currentWeekStart(user).AddDate(0, 0, 7*weekOffset)
+	weekDayNames := weekDayOrder(userWeekStartDay(user))
 
-	// 2. Difficulty Allocation
-	ratio := s.getDifficultyRatio(profile)
+	existing, err := s.GoalRepo.GetWeeklyGoal(ctx, user.ID, weekStart, models.GoalTypeGenerated)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && !opts.Regenerate {
+		return (&GenerationResult{Exhausted: []CategoryShortfall{}}).withGoal(existing), nil
+	}
+	if existing != nil && existing.FinalizedAt != nil {
+		return nil, ErrGoalFinalized
+	}
 
-	// 3. Goals Configuration
-	weeklyProblemCount := 8
+	// Drop goals that fell out of the retention window before adding a new one
+	if existing == nil {
+		if err := s.CleanupOldGoals(ctx, user); err != nil {
+			log.Printf("Goal cleanup failed for %s: %v", user.Username, err)
+		}
+	}
 
-	easyCount := int(math.Round(float64(weeklyProblemCount) * ratio.Easy))
-	mediumCount := int(math.Round(float64(weeklyProblemCount) * ratio.Medium))
-	hardCount := weeklyProblemCount - easyCount - mediumCount
+	// Problems of the replaced goal that are already done stay in the plan on their day
+	kept := make(GoalPlan)
+	keptByDifficulty := make(map[string]int)
+	if existing != nil {
+		if err := s.refreshProgress(ctx, existing); err != nil {
+			return nil, err
+		}
+		plan, err := parseGoalPlan(existing.SelectedProblems)
+		if err != nil {
+			return nil, err
+		}
+		for day, problems := range plan {
+			for _, p := range problems {
+				if p.Completed {
+					kept[day] = append(kept[day], p)
+					keptByDifficulty[p.Difficulty]++
+				}
+			}
+		}
+	}
+
+	// 1. Strategy: the requested one, else the user's choice
+	strategy := opts.Strategy
+	if strategy == "" {
+		strategy = user.GoalStrategy
+	}
+	planner, err := goalPlannerFor(strategy)
+	if err != nil {
+		return nil, err
+	}
+	rng := s.NewRand()
+
+	prefs, err := s.GetPreferences(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Feature Extraction
+	profile := s.buildUserProfile(ctx, user)
+	profile.RecentActivity.AvgDailyTime = prefs.averageMinutes()
+
+	// 3. Problem counts and focus topics, adjusted to the user's preferences
+	spec := prefs.adjustSpec(planner.Plan(profile))
 
 	// 4. Problem Selection Strategy (API Based)
-	// We need to fetch problems per category because we can't filter in-memory from a huge list anymore.
-
-	// Fetch more than needed to allow for some random selection/filtering
-	apiEasy, _ := s.ProblemRepo.GetProblemsByDifficulty(ctx, "Easy", easyCount*3)
-	apiMedium, _ := s.ProblemRepo.GetProblemsByDifficulty(ctx, "Medium", mediumCount*3)
-	apiHard, _ := s.ProblemRepo.GetProblemsByDifficulty(ctx, "Hard", hardCount*3)
-
-	// Weak Topic Injection
-	// If user has weak topics, fetch a few from there and replace some medium/hard
-	if len(profile.WeakTopics) > 0 {
-		topic := profile.WeakTopics[0]
-		topicProblems, _ := s.ProblemRepo.GetProblemsByTopic(ctx, topic, 5)
-		// Naively merge some topics into medium/hard lists
-		for _, tp := range topicProblems {
-			if tp.Difficulty == "Medium" {
-				apiMedium = append(apiMedium, tp)
-			} else if tp.Difficulty == "Hard" {
-				apiHard = append(apiHard, tp)
+	// Solved problems and ones assigned in recent weeks (including the goal being
+	// replaced) are skipped, paging further into the problem list until there are
+	// enough fresh candidates.
+	exclusions, err := s.loadExclusions(ctx, user.ID, weekStart)
+	if err != nil {
+		return nil, err
+	}
+	exclusions.addTopics(prefs.ExcludeTopics)
+
+	type category struct {
+		difficulty string
+		count      int
+	}
+	categories := make([]category, 0, len(goalDifficulties))
+	for _, difficulty := range goalDifficulties {
+		categories = append(categories, category{difficulty, max(spec.Counts[difficulty]-keptByDifficulty[difficulty], 0)})
+	}
+	// Legacy kept problems may not know their difficulty. They still count towards the plan,
+	// taken from whichever difficulty has the most problems left.
+	for unknown := keptByDifficulty[""]; unknown > 0; unknown-- {
+		largest := 0
+		for i := range categories {
+			if categories[i].count > categories[largest].count {
+				largest = i
+			}
+		}
+		if categories[largest].count == 0 {
+			break
+		}
+		categories[largest].count--
+	}
+
+	var poolTopics []string
+	if spec.TopicsOnly {
+		poolTopics = spec.FocusTopics[:1]
+	}
+	pools := make(map[string][]leetcode.APIQuestion)
+	for _, cat := range categories {
+		candidates, err := s.fetchFreshCandidates(ctx, cat.difficulty, poolTopics, cat.count, exclusions, rng)
+		if err != nil {
+			return nil, err
+		}
+		pools[cat.difficulty] = candidates
+	}
+
+	// Focus Topic Injection
+	// Fetch a few problems from each focus topic and merge them into the pools they fit
+	if !spec.TopicsOnly {
+		for _, topic := range spec.FocusTopics {
+			topicProblems, _ := s.fetchFreshCandidates(ctx, "", []string{topic}, 2, exclusions, rng)
+			for _, tp := range topicProblems {
+				if spec.Counts[tp.Difficulty] > 0 {
+					pools[tp.Difficulty] = append(pools[tp.Difficulty], tp)
+				}
 			}
 		}
 	}
 
+	result := &GenerationResult{Exhausted: []CategoryShortfall{}}
 	selectedProblems := make([]leetcode.APIQuestion, 0)
-	selectedProblems = append(selectedProblems, s.selectProblems(apiEasy, easyCount)...)
-	selectedProblems = append(selectedProblems, s.selectProblems(apiMedium, mediumCount)...)
-	selectedProblems = append(selectedProblems, s.selectProblems(apiHard, hardCount)...)
+	breakdown := make(map[string]int)
+	for _, cat := range categories {
+		picked := s.selectProblems(pools[cat.difficulty], cat.count)
+		if len(picked) < cat.count {
+			result.Exhausted = append(result.Exhausted, CategoryShortfall{Category: cat.difficulty, Requested: cat.count, Selected: len(picked)})
+		}
+		breakdown[strings.ToLower(cat.difficulty)] = len(picked) + keptByDifficulty[cat.difficulty]
+		selectedProblems = append(selectedProblems, picked...)
+	}
 
 	// 5. Weekly Distribution
-	dailyPlan := s.distributeAcrossWeek(selectedProblems)
+	// Rest days and daily time budgets override the planner's days. Kept problems stay on
+	// their day and use up its time.
+	dailyPlan := prefs.fitSchedule(kept, planner.Distribute(selectedProblems, weekDayNames, rng), weekDayNames)
 
 	// 6. Construct Goal Objects
-	weekStart := getWeekStart()
-
-	breakdownJSON, _ := json.Marshal(map[string]int{
-		"easy":   easyCount,
-		"medium": mediumCount,
-		"hard":   hardCount,
-	})
+	breakdownJSON, _ := json.Marshal(breakdown)
+	focusTopics := spec.FocusTopics
+	if focusTopics == nil {
+		focusTopics = []string{}
+	}
+	focusTopicsJSON, _ := json.Marshal(focusTopics)
+
+	if existing != nil {
+		existing.Strategy = planner.Name()
+		existing.DifficultyBreakdown = datatypes.JSON(breakdownJSON)
+		existing.FocusTopics = datatypes.JSON(focusTopicsJSON)
+		if err := setGoalPlan(existing, dailyPlan); err != nil {
+			return nil, err
+		}
+		if err := s.GoalRepo.Update(ctx, existing); err != nil {
+			return nil, err
+		}
 
-	selectedProblemsJSON, _ := json.Marshal(dailyPlan)
-	focusTopicsJSON, _ := json.Marshal(profile.WeakTopics)
+		result.Regenerated = true
+		return result.withGoal(existing), nil
+	}
 
 	weeklyGoal := models.WeeklyGoal{
 		UserID:              user.ID,
 		WeekStartDate:       weekStart,
-		GoalType:            "GENERATED",
+		WeekTimezone:        userLocation(user).String(),
+		GoalType:            models.GoalTypeGenerated,
+		Strategy:            planner.Name(),
 		DifficultyBreakdown: datatypes.JSON(breakdownJSON),
-		SelectedProblems:    datatypes.JSON(selectedProblemsJSON),
 		FocusTopics:         datatypes.JSON(focusTopicsJSON),
-		Status:              "PENDING",
+		Status:              models.GoalStatusPending,
 		CreatedAt:           time.Now(),
 	}
+	if err := setGoalPlan(&weeklyGoal, dailyPlan); err != nil {
+		return nil, err
+	}
+
+	created, err := s.GoalRepo.CreateIfAbsent(ctx, &weeklyGoal)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		// A concurrent request created the week's goal first
+		current, err := s.GoalRepo.GetWeeklyGoal(ctx, user.ID, weekStart, models.GoalTypeGenerated)
+		if err != nil {
+			return nil, err
+		}
+		return (&GenerationResult{Exhausted: []CategoryShortfall{}}).withGoal(current), nil
+	}
 
-	return s.GoalRepo.CreateBatch(ctx, []models.WeeklyGoal{weeklyGoal})
+	result.Created = true
+	return result.withGoal(&weeklyGoal), nil
 }
 
-func (s *GoalService) buildUserProfile(user *models.User) UserProfile {
-	// Logic: Analyze TopicStats to find low accuracy topics
-	weakTopics := []string{}
-	// Mock: if easy solved < 10, maybe "Array" is a good start
-	if user.EasySolved < 10 {
-		weakTopics = append(weakTopics, "Array")
+func (s *GoalService) buildUserProfile(ctx context.Context, user *models.User) UserProfile {
+	// Weak and strong topics come from the same mastery report as GET /users/:username/topics,
+	// as tag slugs so they can be passed to the problem API
+	mastery := s.Topics.Mastery(ctx, user)
+	weakTopics := make([]string, 0, len(mastery.Weak))
+	for _, topic := range mastery.Weak {
+		weakTopics = append(weakTopics, topic.TagSlug)
+	}
+	strongTopics := make([]string, 0, len(mastery.Strong))
+	for _, topic := range mastery.Strong {
+		strongTopics = append(strongTopics, topic.TagSlug)
 	}
 
 	return UserProfile{
@@ -158,19 +445,10 @@ func (s *GoalService) buildUserProfile(user *models.User) UserProfile {
 		MediumSolved: user.MediumSolved,
 		HardSolved:   user.HardSolved,
 		WeakTopics:   weakTopics,
+		StrongTopics: strongTopics,
 	}
 }
 
-func (s *GoalService) getDifficultyRatio(user UserProfile) DifficultyRatio {
-	if user.TotalSolved < 150 {
-		return DifficultyRatio{Easy: 0.5, Medium: 0.4, Hard: 0.1}
-	}
-	if user.TotalSolved < 400 {
-		return DifficultyRatio{Easy: 0.3, Medium: 0.5, Hard: 0.2}
-	}
-	return DifficultyRatio{Easy: 0.2, Medium: 0.5, Hard: 0.3}
-}
-
 func (s *GoalService) selectProblems(candidates []leetcode.APIQuestion, count int) []leetcode.APIQuestion {
 	// Deduplicate by ID
 	unique := make(map[string]leetcode.APIQuestion)
@@ -187,7 +465,11 @@ func (s *GoalService) selectProblems(candidates []leetcode.APIQuestion, count in
 	sort.Slice(uniqueList, func(i, j int) bool {
 		// Prefer higher acceptance rate for confidence, or lower for challenge?
 		// Let's mix: Prefer high frequency if available, else random stir
-		return uniqueList[i].AcRate > uniqueList[j].AcRate // Higher AC rate first
+		if uniqueList[i].AcRate != uniqueList[j].AcRate {
+			return uniqueList[i].AcRate > uniqueList[j].AcRate // Higher AC rate first
+		}
+		// Stable order for equal rates, so the same candidates always give the same pick
+		return uniqueList[i].QuestionFrontendId < uniqueList[j].QuestionFrontendId
 	})
 
 	result := []leetcode.APIQuestion{}
@@ -197,69 +479,48 @@ func (s *GoalService) selectProblems(candidates []leetcode.APIQuestion, count in
 	return result
 }
 
-func (s *GoalService) distributeAcrossWeek(problems []leetcode.APIQuestion) map[string][]string {
-	schedule := make(map[string][]string)
-
-	days := []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday"}
-
-	// Buckets
-	easy := []leetcode.APIQuestion{}
-	medium := []leetcode.APIQuestion{}
-	hard := []leetcode.APIQuestion{}
-
-	for _, p := range problems {
-		switch strings.ToLower(p.Difficulty) {
-		case "easy":
-			easy = append(easy, p)
-		case "medium":
-			medium = append(medium, p)
-		case "hard":
-			hard = append(hard, p)
-		}
+func newGoalProblem(q leetcode.APIQuestion) models.GoalProblem {
+	topics := make([]string, 0, len(q.TopicTags))
+	for _, tag := range q.TopicTags {
+		topics = append(topics, tag.Slug)
 	}
-
-	// Monday: Easy
-	if len(easy) > 0 {
-		schedule["Monday"] = append(schedule["Monday"], easy[0].Title)
-		easy = easy[1:]
+	return models.GoalProblem{
+		FrontendID: q.QuestionFrontendId,
+		Title:      q.Title,
+		TitleSlug:  q.TitleSlug,
+		Difficulty: q.Difficulty,
+		Topics:     topics,
+		AcRate:     q.AcRate,
 	}
+}
 
-	// Tue-Thu: Medium Focus
-	midDays := []string{"Tuesday", "Wednesday", "Thursday"}
-	for _, day := range midDays {
-		if len(medium) > 0 {
-			schedule[day] = append(schedule[day], medium[0].Title)
-			medium = medium[1:]
-		}
+// GetUserGoals returns the user's goals for week, GoalWeekCurrent (default) or GoalWeekNext.
+// Goals are matched by the instant the week is looked up at, in the zone each goal was planned
+// in, so goals stay findable after the user changes timezone or first day of the week.
+func (s *GoalService) GetUserGoals(ctx context.Context, user *models.User, week string) ([]GoalResponse, error) {
+	at := s.Now()
+	switch week {
+	case "", GoalWeekCurrent:
+	case GoalWeekNext:
+		_, at = weekBounds(s.currentWeekStart(user), userLocation(user))
+	default:
+		return nil, ErrInvalidGoalWeek
 	}
 
-	// Fill rest
-	remaining := append(easy, append(medium, hard...)...)
-	rand.Shuffle(len(remaining), func(i, j int) { remaining[i], remaining[j] = remaining[j], remaining[i] })
-
-	dayIdx := 0
-	for _, p := range remaining {
-		day := days[dayIdx%7]
-		schedule[day] = append(schedule[day], p.Title)
-		dayIdx++
+	// Every week containing at starts within 7 days of its UTC date
+	day := time.Date(at.UTC().Year(), at.UTC().Month(), at.UTC().Day(), 0, 0, 0, 0, time.UTC)
+	candidates, err := s.GoalRepo.ListStartingBetween(ctx, user.ID, day.AddDate(0, 0, -7), day.AddDate(0, 0, 7))
+	if err != nil {
+		return nil, err
 	}
 
-	return schedule
-}
-
-func getWeekStart() time.Time {
-	now := time.Now().UTC()
-	// Calculate start of the week (Monday)
-	offset := int(time.Monday - now.Weekday())
-	if offset > 0 {
-		offset = -6
+	goals := []GoalResponse{}
+	for i := range candidates {
+		from, to := weekBounds(candidates[i].WeekStartDate, loadLocation(candidates[i].WeekTimezone))
+		if at.Before(from) || !at.Before(to) {
+			continue
+		}
+		goals = append(goals, *newGoalResponse(&candidates[i]))
 	}
-	weekStart := now.AddDate(0, 0, offset)
-	// Truncate to midnight
-	return time.Date(weekStart.Year(), weekStart.Month(), weekStart.Day(), 0, 0, 0, 0, time.UTC)
-}
-
-func (s *GoalService) GetUserGoals(ctx context.Context, userID uuid.UUID) ([]models.WeeklyGoal, error) {
-	weekStart := getWeekStart()
-	return s.GoalRepo.GetWeeklyGoals(ctx, userID, weekStart)
+	return goals, nil
 }
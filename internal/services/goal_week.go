@@ -0,0 +1,69 @@
+package services
+
+import (
+	"time"
+
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/models"
+)
+
+// Goal weeks follow the user's timezone and first day of the week. A week is stored as
+// the calendar date of its first day (midnight UTC in a time.Time, a DATE column in
+// Postgres) plus the zone it was planned in. Instants are only derived from that pair
+// with time.Date, which keeps days whole across DST changes: a week containing a DST
+// switch is 167 or 169 hours long, but always runs from local midnight to local midnight.
+
+// userLocation returns the user's timezone, falling back to UTC when it is unset or unknown
+func userLocation(user *models.User) *time.Location {
+	return loadLocation(user.Timezone)
+}
+
+func loadLocation(name string) *time.Location {
+	if name == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// userWeekStartDay returns the user's first day of the week, Monday by default
+func userWeekStartDay(user *models.User) time.Weekday {
+	for i, day := range weekDays {
+		if day == user.WeekStartDay {
+			// weekDays starts on Monday, time.Weekday on Sunday
+			return time.Weekday((i + 1) % 7)
+		}
+	}
+	return time.Monday
+}
+
+// weekStartDate returns the date the week containing now starts on, in loc
+func weekStartDate(now time.Time, loc *time.Location, startDay time.Weekday) time.Time {
+	local := now.In(loc)
+	offset := (int(local.Weekday()) - int(startDay) + 7) % 7
+	y, m, d := local.Date()
+	return time.Date(y, m, d-offset, 0, 0, 0, 0, time.UTC)
+}
+
+// weekBounds returns the instants the week starting on date begins and ends in loc
+func weekBounds(date time.Time, loc *time.Location) (from, to time.Time) {
+	y, m, d := date.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, loc), time.Date(y, m, d+7, 0, 0, 0, 0, loc)
+}
+
+// weekDayOrder lists the day names of a week that starts on startDay
+func weekDayOrder(startDay time.Weekday) []string {
+	start := (int(startDay) + 6) % 7 // Index in weekDays, which starts on Monday
+	order := make([]string, 0, len(weekDays))
+	for i := range weekDays {
+		order = append(order, weekDays[(start+i)%len(weekDays)])
+	}
+	return order
+}
+
+// currentWeekStart is the date the user's current goal week started on
+func (s *GoalService) currentWeekStart(user *models.User) time.Time {
+	return weekStartDate(s.Now(), userLocation(user), userWeekStartDay(user))
+}
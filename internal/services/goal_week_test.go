@@ -0,0 +1,69 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/models"
+)
+
+func TestCurrentWeekAcrossTimezonesAndDST(t *testing.T) {
+	tests := []struct {
+		name      string
+		timezone  string
+		startDay  string
+		now       string // RFC3339
+		wantStart string
+		wantHours float64
+	}{
+		{"Los Angeles spring forward", "America/Los_Angeles", "Monday", "2026-03-05T20:00:00Z", "2026-03-02", 167},
+		{"Los Angeles fall back", "America/Los_Angeles", "Monday", "2026-10-30T20:00:00Z", "2026-10-26", 169},
+		{"Berlin spring forward", "Europe/Berlin", "Monday", "2026-03-26T12:00:00Z", "2026-03-23", 167},
+		{"Berlin fall back", "Europe/Berlin", "Monday", "2026-10-22T12:00:00Z", "2026-10-19", 169},
+		// Sunday 8 March is the first day and the DST change happens at 02:00 that day
+		{"Los Angeles Sunday week starting on the DST change", "America/Los_Angeles", "Sunday", "2026-03-08T20:00:00Z", "2026-03-08", 167},
+		{"Saturday week", "UTC", "Saturday", "2026-10-14T12:00:00Z", "2026-10-10", 168},
+		// Still Sunday in UTC, already Monday in the user's zone
+		{"Tokyo ahead of UTC", "Asia/Tokyo", "Monday", "2026-10-18T20:00:00Z", "2026-10-19", 168},
+		{"Berlin just after local midnight", "Europe/Berlin", "Monday", "2026-06-14T22:30:00Z", "2026-06-15", 168},
+		{"Unknown timezone falls back to UTC", "Mars/Olympus_Mons", "", "2026-10-18T22:30:00Z", "2026-10-12", 168},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now, err := time.Parse(time.RFC3339, tt.now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := &GoalService{Now: func() time.Time { return now }}
+			user := &models.User{Timezone: tt.timezone, WeekStartDay: tt.startDay}
+
+			start := s.currentWeekStart(user)
+			if got := start.Format(dateLayout); got != tt.wantStart {
+				t.Fatalf("week start = %s, want %s", got, tt.wantStart)
+			}
+
+			loc := userLocation(user)
+			from, to := weekBounds(start, loc)
+			if hours := to.Sub(from).Hours(); hours != tt.wantHours {
+				t.Errorf("week is %vh long, want %vh", hours, tt.wantHours)
+			}
+			if local := from.In(loc); local.Hour() != 0 || local.Minute() != 0 || local.Weekday() != userWeekStartDay(user) {
+				t.Errorf("week starts at %s, want local midnight on %s", local, userWeekStartDay(user))
+			}
+			if now.Before(from) || !now.Before(to) {
+				t.Errorf("now %s is outside the week [%s, %s)", now, from, to)
+			}
+		})
+	}
+}
+
+func TestWeekDayOrder(t *testing.T) {
+	got := weekDayOrder(time.Sunday)
+	want := []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("weekDayOrder(Sunday) = %v, want %v", got, want)
+		}
+	}
+}
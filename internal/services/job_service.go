@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/config"
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/jobs"
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/models"
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/repository"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+var ErrJobNotFound = errors.New("job not found")
+
+// JobService enqueues slow work (LeetCode sync, goal generation, AI comparisons)
+// and implements the handlers the worker runs for it
+type JobService struct {
+	JobRepo           repository.JobRepository
+	UserRepo          repository.UserRepository
+	UserService       *UserService
+	GoalService       *GoalService
+	ComparisonService *ComparisonService
+	MaxAttempts       int
+	StaleAfter        time.Duration
+	Retention         time.Duration
+}
+
+func NewJobService(jobRepo repository.JobRepository, userRepo repository.UserRepository, userService *UserService, goalService *GoalService, comparisonService *ComparisonService, cfg *config.Config) *JobService {
+	return &JobService{
+		JobRepo:           jobRepo,
+		UserRepo:          userRepo,
+		UserService:       userService,
+		GoalService:       goalService,
+		ComparisonService: comparisonService,
+		MaxAttempts:       cfg.JobMaxAttempts,
+		StaleAfter:        cfg.JobStaleAfter,
+		Retention:         cfg.JobRetention,
+	}
+}
+
+// SyncJobPayload is the payload of a user.sync job
+type SyncJobPayload struct {
+	Username string `json:"username"`
+}
+
+// GoalsJobPayload is the payload of a goals.generate job
+type GoalsJobPayload struct {
+	UserID  uuid.UUID         `json:"user_id"`
+	Options GenerationOptions `json:"options"`
+}
+
+// ComparisonJobPayload is the payload of a comparison.run job
+type ComparisonJobPayload struct {
+	User1Name string `json:"user1_name"`
+	User1Data string `json:"user1_data"`
+	User2Name string `json:"user2_name"`
+	User2Data string `json:"user2_data"`
+}
+
+// RegisterHandlers wires every job type this service enqueues into the worker
+func (s *JobService) RegisterHandlers(w *jobs.Worker) {
+	w.Register(models.JobTypeUserSync, s.handleUserSync)
+	w.Register(models.JobTypeGoalsGenerate, s.handleGoalsGenerate)
+	w.Register(models.JobTypeComparisonRun, s.handleComparison)
+}
+
+// EnqueueSync queues a LeetCode sync for the user, reusing one that is already pending
+func (s *JobService) EnqueueSync(ctx context.Context, user *models.User) (*models.Job, error) {
+	return s.enqueueOnce(ctx, models.JobTypeUserSync, user, SyncJobPayload{Username: user.Username})
+}
+
+// EnqueueGoalGeneration queues weekly goal generation for the user, reusing a pending one with the same options
+func (s *JobService) EnqueueGoalGeneration(ctx context.Context, user *models.User, opts GenerationOptions) (*models.Job, error) {
+	payload := GoalsJobPayload{UserID: user.ID, Options: opts}
+
+	existing, err := s.JobRepo.FindActive(ctx, models.JobTypeGoalsGenerate, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		var pending GoalsJobPayload
+		if err := json.Unmarshal(existing.Payload, &pending); err == nil && pending == payload {
+			return existing, nil
+		}
+	}
+	return s.enqueue(ctx, models.JobTypeGoalsGenerate, user, payload)
+}
+
+// EnqueueComparison queues an AI comparison requested by user
+func (s *JobService) EnqueueComparison(ctx context.Context, user *models.User, payload ComparisonJobPayload) (*models.Job, error) {
+	return s.enqueue(ctx, models.JobTypeComparisonRun, user, payload)
+}
+
+// GetJob returns a job owned by user. Admins can read any job.
+func (s *JobService) GetJob(ctx context.Context, user *models.User, id uuid.UUID) (*models.Job, error) {
+	job, err := s.JobRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil || (user.Role != models.RoleAdmin && (job.UserID == nil || *job.UserID != user.ID)) {
+		return nil, ErrJobNotFound
+	}
+	return job, nil
+}
+
+// Maintain requeues jobs abandoned by crashed workers and deletes old finished jobs
+func (s *JobService) Maintain(ctx context.Context) error {
+	if _, err := s.JobRepo.RequeueStale(ctx, time.Now().Add(-s.StaleAfter)); err != nil {
+		return fmt.Errorf("failed to requeue stale jobs: %w", err)
+	}
+	if _, err := s.JobRepo.DeleteFinishedBefore(ctx, time.Now().Add(-s.Retention)); err != nil {
+		return fmt.Errorf("failed to delete finished jobs: %w", err)
+	}
+	return nil
+}
+
+func (s *JobService) enqueueOnce(ctx context.Context, jobType string, user *models.User, payload interface{}) (*models.Job, error) {
+	existing, err := s.JobRepo.FindActive(ctx, jobType, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return existing, nil
+	}
+	return s.enqueue(ctx, jobType, user, payload)
+}
+
+func (s *JobService) enqueue(ctx context.Context, jobType string, user *models.User, payload interface{}) (*models.Job, error) {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &models.Job{
+		Type:        jobType,
+		UserID:      &user.ID,
+		Payload:     datatypes.JSON(encoded),
+		MaxAttempts: s.MaxAttempts,
+	}
+	if err := s.JobRepo.Enqueue(ctx, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func (s *JobService) handleUserSync(ctx context.Context, job *models.Job) (interface{}, error) {
+	var payload SyncJobPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return nil, jobs.Permanent(fmt.Errorf("invalid payload: %w", err))
+	}
+
+	user, err := s.UserService.SyncUser(ctx, payload.Username)
+	if errors.Is(err, ErrUserNotFound) {
+		return nil, jobs.Permanent(err)
+	}
+	return user, err
+}
+
+func (s *JobService) handleGoalsGenerate(ctx context.Context, job *models.Job) (interface{}, error) {
+	var payload GoalsJobPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return nil, jobs.Permanent(fmt.Errorf("invalid payload: %w", err))
+	}
+
+	user, err := s.UserRepo.GetByID(ctx, payload.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, jobs.Permanent(ErrUserNotFound)
+	}
+
+	result, err := s.GoalService.GenerateWeeklyGoals(ctx, user.ID, payload.Options)
+	if errors.Is(err, ErrUserNotFound) || errors.Is(err, ErrInvalidGoalWeek) || errors.Is(err, ErrGoalWeekRetained) || errors.Is(err, ErrGoalFinalized) ||
+		errors.Is(err, ErrUnknownStrategy) {
+		return nil, jobs.Permanent(err)
+	}
+	return result, err
+}
+
+func (s *JobService) handleComparison(ctx context.Context, job *models.Job) (interface{}, error) {
+	var payload ComparisonJobPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return nil, jobs.Permanent(fmt.Errorf("invalid payload: %w", err))
+	}
+
+	return s.ComparisonService.CompareUsers(ctx, payload.User1Name, payload.User1Data, payload.User2Name, payload.User2Data)
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/config"
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/models"
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/repository"
+	"github.com/google/uuid"
+)
+
+const (
+	FailureUnknownUser = "UNKNOWN_USER"
+	FailureBadPassword = "BAD_PASSWORD"
+	FailureBad2FACode  = "BAD_2FA_CODE"
+	FailureLocked      = "LOCKED"
+)
+
+// LockedError is returned while an account or IP is temporarily locked out
+type LockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("too many failed login attempts, try again in %s", e.RetryAfter.Round(time.Second))
+}
+
+// LoginGuard throttles logins per account and per IP with exponential lockouts
+// and keeps an audit trail of failures.
+type LoginGuard struct {
+	Store           repository.LoginAttemptStore
+	FailedLoginRepo repository.FailedLoginRepository
+	MaxFailures     int
+	MaxIPFailures   int
+	LockoutBase     time.Duration
+	LockoutMax      time.Duration
+	FailureWindow   time.Duration
+}
+
+func NewLoginGuard(store repository.LoginAttemptStore, failedLoginRepo repository.FailedLoginRepository, cfg *config.Config) *LoginGuard {
+	return &LoginGuard{
+		Store:           store,
+		FailedLoginRepo: failedLoginRepo,
+		MaxFailures:     cfg.LoginMaxFailures,
+		MaxIPFailures:   cfg.LoginMaxIPFailures,
+		LockoutBase:     cfg.LoginLockoutBase,
+		LockoutMax:      cfg.LoginLockoutMax,
+		FailureWindow:   cfg.LoginFailureWindow,
+	}
+}
+
+// LoginAttempt identifies who is trying to log in and from where
+type LoginAttempt struct {
+	Identifier string
+	User       *models.User // Nil when the identifier matched no user
+	Client     ClientInfo
+}
+
+// accountKey counts failures against the resolved user when possible, so switching
+// between username and email does not reset the counter
+func (a LoginAttempt) accountKey() string {
+	if a.User != nil {
+		return "user:" + a.User.ID.String()
+	}
+	return "identifier:" + strings.ToLower(strings.TrimSpace(a.Identifier))
+}
+
+func (a LoginAttempt) ipKey() string {
+	return "ip:" + a.Client.IPAddress
+}
+
+// Check returns a *LockedError if the account or the IP is currently locked out
+func (g *LoginGuard) Check(ctx context.Context, attempt LoginAttempt) error {
+	now := time.Now()
+	var retryAfter time.Duration
+
+	for _, key := range []string{attempt.accountKey(), attempt.ipKey()} {
+		counter, err := g.Store.Get(ctx, key)
+		if err != nil {
+			return err
+		}
+		if counter != nil && counter.LockedUntil != nil && counter.LockedUntil.After(now) {
+			if wait := counter.LockedUntil.Sub(now); wait > retryAfter {
+				retryAfter = wait
+			}
+		}
+	}
+
+	if retryAfter > 0 {
+		g.audit(ctx, attempt, FailureLocked)
+		return &LockedError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// Fail records a failed attempt and locks the account/IP once their thresholds are crossed
+func (g *LoginGuard) Fail(ctx context.Context, attempt LoginAttempt, reason string) error {
+	g.audit(ctx, attempt, reason)
+
+	now := time.Now()
+	if err := g.increment(ctx, attempt.accountKey(), g.MaxFailures, now); err != nil {
+		return err
+	}
+	return g.increment(ctx, attempt.ipKey(), g.MaxIPFailures, now)
+}
+
+// Succeed clears the account counter. The IP counter is left alone so a single valid
+// account cannot be used to reset a password spraying run.
+func (g *LoginGuard) Succeed(ctx context.Context, attempt LoginAttempt) error {
+	return g.Store.Reset(ctx, attempt.accountKey())
+}
+
+// FailedLogins returns the most recent failed attempts against the user's account
+func (g *LoginGuard) FailedLogins(ctx context.Context, userID uuid.UUID, limit int) ([]models.FailedLogin, error) {
+	return g.FailedLoginRepo.ListByUser(ctx, userID, limit)
+}
+
+func (g *LoginGuard) increment(ctx context.Context, key string, threshold int, now time.Time) error {
+	counter, err := g.Store.Increment(ctx, key, now, g.FailureWindow)
+	if err != nil {
+		return err
+	}
+	if counter.Failures < threshold {
+		return nil
+	}
+	return g.Store.LockUntil(ctx, key, now.Add(g.lockoutFor(counter.Failures-threshold)))
+}
+
+// lockoutFor doubles the lockout for every failure past the threshold, up to LockoutMax
+func (g *LoginGuard) lockoutFor(excess int) time.Duration {
+	d := float64(g.LockoutBase) * math.Pow(2, float64(excess))
+	if d > float64(g.LockoutMax) {
+		return g.LockoutMax
+	}
+	return time.Duration(d)
+}
+
+func (g *LoginGuard) audit(ctx context.Context, attempt LoginAttempt, reason string) {
+	entry := &models.FailedLogin{
+		Identifier: attempt.Identifier,
+		IPAddress:  attempt.Client.IPAddress,
+		UserAgent:  attempt.Client.UserAgent,
+		Reason:     reason,
+		CreatedAt:  time.Now(),
+	}
+	if attempt.User != nil {
+		entry.UserID = &attempt.User.ID
+	}
+	if err := g.FailedLoginRepo.Create(ctx, entry); err != nil {
+		log.Printf("Failed to record failed login for %q: %v", attempt.Identifier, err)
+	}
+}
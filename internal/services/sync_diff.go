@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/clients/leetcode"
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/models"
+	"gorm.io/datatypes"
+)
+
+const (
+	defaultSyncEventLimit = 20
+	maxSyncEventLimit     = 100
+)
+
+// SyncResult is the synced user plus what changed since the previous sync.
+// The user's fields are inlined in JSON so existing clients keep working.
+type SyncResult struct {
+	*models.User
+	Diff *SyncDiff `json:"diff"`
+}
+
+// SyncDiff describes how a user's stats moved between two syncs
+type SyncDiff struct {
+	PreviousSyncAt *time.Time `json:"previous_sync_at"` // Nil on the first sync, when every delta is relative to zero
+
+	TotalSolved  int `json:"total_solved"`
+	EasySolved   int `json:"easy_solved"`
+	MediumSolved int `json:"medium_solved"`
+	HardSolved   int `json:"hard_solved"`
+
+	Topics []TopicDelta `json:"topics"` // Only topics whose solved count increased
+
+	ContestRating float64  `json:"contest_rating"`
+	Ranking       *int     `json:"ranking,omitempty"` // New minus old global ranking, so negative means the user climbed. Nil without an old ranking.
+	Streak        int      `json:"streak"`
+	NewBadges     []string `json:"new_badges"`
+}
+
+// TopicDelta is the change in solved problems for one SkillTags topic
+type TopicDelta struct {
+	TagSlug string `json:"tag_slug"`
+	TagName string `json:"tag_name"`
+	Solved  int    `json:"solved"` // Newly solved since the previous sync
+	Total   int    `json:"total"`  // Solved in total now
+}
+
+// IsEmpty reports whether nothing changed
+func (d *SyncDiff) IsEmpty() bool {
+	return d.TotalSolved == 0 && d.EasySolved == 0 && d.MediumSolved == 0 && d.HardSolved == 0 &&
+		len(d.Topics) == 0 && d.ContestRating == 0 && (d.Ranking == nil || *d.Ranking == 0) && d.Streak == 0 && len(d.NewBadges) == 0
+}
+
+// ListSyncEvents returns the user's recorded sync diffs, newest first
+func (s *UserService) ListSyncEvents(ctx context.Context, user *models.User, since time.Time, limit int) ([]models.SyncEvent, error) {
+	if limit < 1 {
+		limit = defaultSyncEventLimit
+	}
+	if limit > maxSyncEventLimit {
+		limit = maxSyncEventLimit
+	}
+	return s.SyncEventRepo.ListByUser(ctx, user.ID, since, limit)
+}
+
+// diffUsers compares the state before and after a sync
+func diffUsers(before, after *models.User) *SyncDiff {
+	diff := &SyncDiff{
+		PreviousSyncAt: before.LastSyncedAt,
+		TotalSolved:    after.TotalSolved - before.TotalSolved,
+		EasySolved:     after.EasySolved - before.EasySolved,
+		MediumSolved:   after.MediumSolved - before.MediumSolved,
+		HardSolved:     after.HardSolved - before.HardSolved,
+		ContestRating:  after.ContestRating - before.ContestRating,
+		Streak:         after.Streak - before.Streak,
+		Topics:         []TopicDelta{},
+		NewBadges:      []string{},
+	}
+
+	// Against a missing old ranking the delta would be the whole ranking
+	if before.LastSyncedAt != nil && before.Ranking != 0 {
+		ranking := after.Ranking - before.Ranking
+		diff.Ranking = &ranking
+	}
+
+	oldTopics := skillTagsBySlug(before.SkillTags)
+	for slug, tag := range skillTagsBySlug(after.SkillTags) {
+		if delta := tag.ProblemsSolved - oldTopics[slug].ProblemsSolved; delta > 0 {
+			diff.Topics = append(diff.Topics, TopicDelta{TagSlug: slug, TagName: tag.TagName, Solved: delta, Total: tag.ProblemsSolved})
+		}
+	}
+	sort.Slice(diff.Topics, func(i, j int) bool {
+		if diff.Topics[i].Solved != diff.Topics[j].Solved {
+			return diff.Topics[i].Solved > diff.Topics[j].Solved
+		}
+		return diff.Topics[i].TagSlug < diff.Topics[j].TagSlug
+	})
+
+	oldBadges := map[string]bool{}
+	for _, name := range badgeNames(before.Badges) {
+		oldBadges[name] = true
+	}
+	for _, name := range badgeNames(after.Badges) {
+		if !oldBadges[name] {
+			diff.NewBadges = append(diff.NewBadges, name)
+		}
+	}
+
+	return diff
+}
+
+// recordSyncEvent stores the diff when something changed. Like snapshots, failures only get logged.
+func (s *UserService) recordSyncEvent(ctx context.Context, user *models.User, diff *SyncDiff) {
+	if diff.IsEmpty() {
+		return
+	}
+
+	encoded, err := json.Marshal(diff)
+	if err != nil {
+		log.Printf("Failed to encode sync diff for %s: %v", user.Username, err)
+		return
+	}
+
+	event := &models.SyncEvent{UserID: user.ID, Diff: datatypes.JSON(encoded), CreatedAt: time.Now()}
+	if err := s.SyncEventRepo.Create(ctx, event); err != nil {
+		log.Printf("Failed to record sync event for %s: %v", user.Username, err)
+	}
+}
+
+// skillTagsBySlug flattens the SkillTags tiers into a lookup by tag slug
+func skillTagsBySlug(skillTags datatypes.JSON) map[string]leetcode.SkillStats {
+	var tiers map[string][]leetcode.SkillStats
+	_ = json.Unmarshal(skillTags, &tiers)
+
+	tags := map[string]leetcode.SkillStats{}
+	for _, tier := range tiers {
+		for _, tag := range tier {
+			tags[tag.TagSlug] = tag
+		}
+	}
+	return tags
+}
+
+func badgeNames(badges datatypes.JSON) []string {
+	var parsed []struct {
+		Name string `json:"name"`
+	}
+	_ = json.Unmarshal(badges, &parsed)
+
+	names := make([]string, 0, len(parsed))
+	for _, badge := range parsed {
+		names = append(names, badge.Name)
+	}
+	return names
+}
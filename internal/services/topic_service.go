@@ -0,0 +1,282 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/clients/leetcode"
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/models"
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/repository"
+)
+
+// Mastery levels of a topic
+const (
+	TopicLevelWeak       = "weak"
+	TopicLevelDeveloping = "developing"
+	TopicLevelStrong     = "strong"
+)
+
+const (
+	// Scores are capped so a single heavily practiced topic does not dominate the strong ranking
+	maxTopicScore = 2.0
+	// Topics returned in the weak and strong rankings
+	maxRankedTopics = 5
+	// Catalog sizes fetched from the problem API at once
+	catalogFetchConcurrency = 4
+)
+
+// topicTier describes one tier of LeetCode's tagProblemCounts
+type topicTier struct {
+	name string
+	// Expected share of the user's solved problems in a topic, relative to the topic's share
+	// of the catalog. Harder tiers are expected to be practiced less.
+	weight float64
+	// Catalog share assumed for the tier's topics when the problem API cannot be reached
+	fallbackShare float64
+	// Total solved below which the tier's topics are not ranked as weak, so beginners
+	// are not pushed towards advanced topics
+	minSolved int
+	// Topics reported even without a solve, since LeetCode only lists tags with at least one
+	core []leetcode.SkillStats
+}
+
+// topicTiers lists the tiers easiest first
+var topicTiers = []topicTier{
+	{
+		name: "fundamental", weight: 1.0, fallbackShare: 0.15, minSolved: 0,
+		core: []leetcode.SkillStats{
+			{TagName: "Array", TagSlug: "array"},
+			{TagName: "String", TagSlug: "string"},
+			{TagName: "Sorting", TagSlug: "sorting"},
+			{TagName: "Two Pointers", TagSlug: "two-pointers"},
+			{TagName: "Linked List", TagSlug: "linked-list"},
+			{TagName: "Stack", TagSlug: "stack"},
+		},
+	},
+	{
+		name: "intermediate", weight: 0.8, fallbackShare: 0.10, minSolved: 50,
+		core: []leetcode.SkillStats{
+			{TagName: "Hash Table", TagSlug: "hash-table"},
+			{TagName: "Binary Search", TagSlug: "binary-search"},
+			{TagName: "Tree", TagSlug: "tree"},
+			{TagName: "Sliding Window", TagSlug: "sliding-window"},
+			{TagName: "Greedy", TagSlug: "greedy"},
+			{TagName: "Breadth-First Search", TagSlug: "breadth-first-search"},
+			{TagName: "Depth-First Search", TagSlug: "depth-first-search"},
+			{TagName: "Heap (Priority Queue)", TagSlug: "heap-priority-queue"},
+		},
+	},
+	{
+		name: "advanced", weight: 0.6, fallbackShare: 0.05, minSolved: 150,
+		core: []leetcode.SkillStats{
+			{TagName: "Dynamic Programming", TagSlug: "dynamic-programming"},
+			{TagName: "Backtracking", TagSlug: "backtracking"},
+			{TagName: "Graph", TagSlug: "graph"},
+			{TagName: "Trie", TagSlug: "trie"},
+			{TagName: "Union Find", TagSlug: "union-find"},
+		},
+	},
+}
+
+// TopicMastery is how well the user covers one topic relative to their level
+type TopicMastery struct {
+	TagName  string  `json:"tag_name"`
+	TagSlug  string  `json:"tag_slug"`
+	Tier     string  `json:"tier"`
+	Solved   int     `json:"solved"`
+	Total    int     `json:"total"`    // Problems with this tag, 0 when the problem API could not be reached
+	Expected int     `json:"expected"` // Solved count expected from the user's total
+	Score    float64 `json:"score"`    // Solved / Expected, capped at 2
+	Level    string  `json:"level"`    // weak, developing or strong
+}
+
+// TopicReport lists every topic and the weakest and strongest ones
+type TopicReport struct {
+	Topics []TopicMastery `json:"topics"` // By tier, then name
+	Weak   []TopicMastery `json:"weak"`   // Lowest score first
+	Strong []TopicMastery `json:"strong"` // Highest score first
+}
+
+// TopicService scores topic mastery from the per-tag solved counts stored by sync.
+// Goal generation and the topics endpoint both use it.
+type TopicService struct {
+	UserRepo    repository.UserRepository
+	ProblemRepo repository.ProblemRepository
+}
+
+func NewTopicService(userRepo repository.UserRepository, problemRepo repository.ProblemRepository) *TopicService {
+	return &TopicService{
+		UserRepo:    userRepo,
+		ProblemRepo: problemRepo,
+	}
+}
+
+// GetTopics returns the topic mastery report of a user
+func (s *TopicService) GetTopics(ctx context.Context, username string) (*TopicReport, error) {
+	user, err := s.UserRepo.GetByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return s.Mastery(ctx, user), nil
+}
+
+// Mastery scores each topic as solved / expected. A user is expected to have solved a topic
+// in proportion to its share of the problem catalog, weighted by the topic's tier, so the
+// expectation grows with the user's total. Topics of tiers the user has not reached yet are
+// reported but never ranked as weak.
+func (s *TopicService) Mastery(ctx context.Context, user *models.User) *TopicReport {
+	var tagCounts map[string][]leetcode.SkillStats
+	_ = json.Unmarshal(user.SkillTags, &tagCounts)
+
+	totalSolved := user.EasySolved + user.MediumSolved + user.HardSolved
+
+	type entry struct {
+		tier topicTier
+		tag  leetcode.SkillStats
+	}
+	var entries []entry
+	seen := make(map[string]bool)
+	add := func(tier topicTier, tags []leetcode.SkillStats) {
+		for _, tag := range tags {
+			if tag.TagSlug == "" || seen[tag.TagSlug] {
+				continue
+			}
+			seen[tag.TagSlug] = true
+			entries = append(entries, entry{tier: tier, tag: tag})
+		}
+	}
+	// The user's counts come first so a core topic never shadows one LeetCode put in another tier
+	for _, tier := range topicTiers {
+		add(tier, tagCounts[tier.name])
+	}
+	for _, tier := range topicTiers {
+		add(tier, tier.core)
+	}
+
+	slugs := make([]string, 0, len(entries))
+	for _, e := range entries {
+		slugs = append(slugs, e.tag.TagSlug)
+	}
+	// The empty topic is the whole catalog
+	catalog := s.catalogSizes(ctx, append(slugs, ""))
+
+	report := &TopicReport{Topics: []TopicMastery{}, Weak: []TopicMastery{}, Strong: []TopicMastery{}}
+	for _, e := range entries {
+		total := catalog[e.tag.TagSlug]
+		share := e.tier.fallbackShare
+		if total > 0 && catalog[""] > 0 {
+			share = float64(total) / float64(catalog[""])
+		}
+		expected := int(math.Max(math.Round(float64(totalSolved)*share*e.tier.weight), 1))
+		score := math.Min(float64(e.tag.ProblemsSolved)/float64(expected), maxTopicScore)
+
+		mastery := TopicMastery{
+			TagName:  e.tag.TagName,
+			TagSlug:  e.tag.TagSlug,
+			Tier:     e.tier.name,
+			Solved:   e.tag.ProblemsSolved,
+			Total:    total,
+			Expected: expected,
+			Score:    math.Round(score*100) / 100,
+			Level:    TopicLevelDeveloping,
+		}
+		switch {
+		case score < 0.5:
+			mastery.Level = TopicLevelWeak
+		case score >= 1:
+			mastery.Level = TopicLevelStrong
+		}
+		report.Topics = append(report.Topics, mastery)
+
+		if mastery.Level == TopicLevelWeak && totalSolved >= e.tier.minSolved {
+			report.Weak = append(report.Weak, mastery)
+		}
+		if mastery.Level == TopicLevelStrong {
+			report.Strong = append(report.Strong, mastery)
+		}
+	}
+
+	tierRank := make(map[string]int)
+	for i, tier := range topicTiers {
+		tierRank[tier.name] = i
+	}
+	sort.SliceStable(report.Topics, func(i, j int) bool {
+		a, b := report.Topics[i], report.Topics[j]
+		if a.Tier != b.Tier {
+			return tierRank[a.Tier] < tierRank[b.Tier]
+		}
+		return a.TagName < b.TagName
+	})
+	// Equal scores favour the easier tier, since gaps in the fundamentals matter most
+	sort.SliceStable(report.Weak, func(i, j int) bool {
+		a, b := report.Weak[i], report.Weak[j]
+		if a.Score != b.Score {
+			return a.Score < b.Score
+		}
+		if a.Tier != b.Tier {
+			return tierRank[a.Tier] < tierRank[b.Tier]
+		}
+		return a.TagName < b.TagName
+	})
+	sort.SliceStable(report.Strong, func(i, j int) bool {
+		a, b := report.Strong[i], report.Strong[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Solved != b.Solved {
+			return a.Solved > b.Solved
+		}
+		return a.TagName < b.TagName
+	})
+	if len(report.Weak) > maxRankedTopics {
+		report.Weak = report.Weak[:maxRankedTopics]
+	}
+	if len(report.Strong) > maxRankedTopics {
+		report.Strong = report.Strong[:maxRankedTopics]
+	}
+	return report
+}
+
+// catalogSizes looks up the number of problems per topic. Topics whose count could not be
+// fetched are left out, and their expectation falls back to the tier default.
+func (s *TopicService) catalogSizes(ctx context.Context, slugs []string) map[string]int {
+	sizes := make(map[string]int, len(slugs))
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		failures int
+		lastErr  error
+	)
+	sem := make(chan struct{}, catalogFetchConcurrency)
+	for _, slug := range slugs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(slug string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			count, err := s.ProblemRepo.CountByTopic(ctx, slug)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failures++
+				lastErr = err
+				return
+			}
+			sizes[slug] = count
+		}(slug)
+	}
+	wg.Wait()
+
+	if failures > 0 {
+		log.Printf("Failed to fetch catalog size of %d topics: %v", failures, lastErr)
+	}
+	return sizes
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/models"
+)
+
+const dateLayout = "2006-01-02"
+
+// defaultHeatmapWindow is used when the caller passes neither a year nor a range
+const defaultHeatmapWindow = 365 * 24 * time.Hour
+
+// HeatmapDay is one cell of the submission heatmap
+type HeatmapDay struct {
+	Date        string `json:"date"`
+	Submissions int    `json:"submissions"`
+}
+
+// Heatmap lists the days with submissions within a range
+type Heatmap struct {
+	From             string       `json:"from"`
+	To               string       `json:"to"`
+	Days             []HeatmapDay `json:"days"`
+	ActiveDays       int          `json:"active_days"`
+	TotalSubmissions int          `json:"total_submissions"`
+}
+
+// ActivityStats summarizes a user's whole recorded submission calendar
+type ActivityStats struct {
+	CurrentStreak    int              `json:"current_streak"`
+	LongestStreak    int              `json:"longest_streak"`
+	ActiveDays       int              `json:"active_days"`
+	TotalSubmissions int              `json:"total_submissions"`
+	Months           []PeriodActivity `json:"months"`   // Oldest first, only months with activity
+	Weekdays         []PeriodActivity `json:"weekdays"` // Monday to Sunday
+	LastActiveDate   string           `json:"last_active_date,omitempty"`
+}
+
+// PeriodActivity aggregates active days and submissions over a month or weekday
+type PeriodActivity struct {
+	Period      string `json:"period"` // "2024-05" for months, "Monday" for weekdays
+	ActiveDays  int    `json:"active_days"`
+	Submissions int    `json:"submissions"`
+}
+
+// GetHeatmap returns the user's daily submissions between from and to.
+// Zero bounds default to the last 365 days.
+func (s *UserService) GetHeatmap(ctx context.Context, username string, from, to time.Time) (*Heatmap, error) {
+	user, err := s.UserRepo.GetByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	if to.IsZero() {
+		to = time.Now().UTC()
+	}
+	if from.IsZero() {
+		from = to.Add(-defaultHeatmapWindow)
+	}
+	from, to = truncateDay(from), truncateDay(to)
+
+	days, err := s.DailyActivityRepo.ListByUser(ctx, user.ID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	heatmap := &Heatmap{From: from.Format(dateLayout), To: to.Format(dateLayout), Days: []HeatmapDay{}}
+	for _, day := range days {
+		if day.Submissions == 0 {
+			continue
+		}
+		heatmap.Days = append(heatmap.Days, HeatmapDay{Date: day.Date.Format(dateLayout), Submissions: day.Submissions})
+		heatmap.ActiveDays++
+		heatmap.TotalSubmissions += day.Submissions
+	}
+	return heatmap, nil
+}
+
+// GetActivityStats computes streaks and distributions from everything recorded for the user
+func (s *UserService) GetActivityStats(ctx context.Context, username string) (*ActivityStats, error) {
+	user, err := s.UserRepo.GetByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	days, err := s.DailyActivityRepo.ListByUser(ctx, user.ID, time.Time{}, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	stats := computeActivityStats(days, time.Now())
+	return &stats, nil
+}
+
+// ingestCalendar normalizes the user's SubmissionCalendar into daily rows and
+// recomputes the streak from them instead of trusting the upstream value
+func (s *UserService) ingestCalendar(ctx context.Context, user *models.User) error {
+	days, err := parseSubmissionCalendar(user)
+	if err != nil {
+		return err
+	}
+	if err := s.DailyActivityRepo.Upsert(ctx, days); err != nil {
+		return err
+	}
+
+	// The upstream calendar only covers the last year, the table keeps older days too
+	all, err := s.DailyActivityRepo.ListByUser(ctx, user.ID, time.Time{}, time.Time{})
+	if err != nil {
+		return err
+	}
+
+	user.Streak = computeActivityStats(all, time.Now()).CurrentStreak
+	return nil
+}
+
+// parseSubmissionCalendar decodes LeetCode's {"<unix midnight UTC>": count} calendar
+func parseSubmissionCalendar(user *models.User) ([]models.UserDailyActivity, error) {
+	if len(user.SubmissionCalendar) == 0 {
+		return nil, nil
+	}
+
+	var calendar map[string]int
+	if err := json.Unmarshal(user.SubmissionCalendar, &calendar); err != nil {
+		return nil, fmt.Errorf("invalid submission calendar: %w", err)
+	}
+
+	now := time.Now()
+	days := make([]models.UserDailyActivity, 0, len(calendar))
+	for key, count := range calendar {
+		ts, err := strconv.ParseInt(key, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid submission calendar timestamp %q: %w", key, err)
+		}
+		days = append(days, models.UserDailyActivity{
+			UserID:      user.ID,
+			Date:        truncateDay(time.Unix(ts, 0)),
+			Submissions: count,
+			UpdatedAt:   now,
+		})
+	}
+	return days, nil
+}
+
+// computeActivityStats expects days sorted by date. The current streak counts back from
+// today, or from yesterday if the user has not submitted yet today.
+func computeActivityStats(days []models.UserDailyActivity, now time.Time) ActivityStats {
+	stats := ActivityStats{Months: []PeriodActivity{}, Weekdays: make([]PeriodActivity, 7)}
+	for i := range stats.Weekdays {
+		// time.Weekday starts on Sunday, the distribution starts on Monday
+		stats.Weekdays[i].Period = time.Weekday((i + 1) % 7).String()
+	}
+
+	var run int
+	var last time.Time
+	for _, day := range days {
+		if day.Submissions == 0 {
+			continue
+		}
+		date := truncateDay(day.Date)
+
+		stats.ActiveDays++
+		stats.TotalSubmissions += day.Submissions
+
+		month := date.Format("2006-01")
+		if n := len(stats.Months); n == 0 || stats.Months[n-1].Period != month {
+			stats.Months = append(stats.Months, PeriodActivity{Period: month})
+		}
+		stats.Months[len(stats.Months)-1].ActiveDays++
+		stats.Months[len(stats.Months)-1].Submissions += day.Submissions
+
+		weekday := &stats.Weekdays[(int(date.Weekday())+6)%7]
+		weekday.ActiveDays++
+		weekday.Submissions += day.Submissions
+
+		if !last.IsZero() && date.Equal(last.AddDate(0, 0, 1)) {
+			run++
+		} else {
+			run = 1
+		}
+		if run > stats.LongestStreak {
+			stats.LongestStreak = run
+		}
+		last = date
+	}
+
+	if !last.IsZero() {
+		stats.LastActiveDate = last.Format(dateLayout)
+		today := truncateDay(now)
+		if last.Equal(today) || last.Equal(today.AddDate(0, 0, -1)) {
+			stats.CurrentStreak = run
+		}
+	}
+	return stats
+}
+
+// truncateDay returns midnight UTC of t's UTC day
+func truncateDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/models"
+	"gorm.io/datatypes"
+)
+
+var ErrUnknownMetric = errors.New("unknown metric")
+
+// defaultHistoryWindow is used when the caller does not pass a "from" bound
+const defaultHistoryWindow = 90 * 24 * time.Hour
+
+// HistoryPoint is one sample of a metric's time series
+type HistoryPoint struct {
+	Timestamp time.Time `json:"timestamp"`
+	Value     float64   `json:"value"`
+}
+
+// HistoryMetrics lists the metrics accepted by GetHistory besides "topic:<tag-slug>"
+var HistoryMetrics = map[string]func(*models.UserStatSnapshot) float64{
+	"total_solved":   func(s *models.UserStatSnapshot) float64 { return float64(s.TotalSolved) },
+	"easy_solved":    func(s *models.UserStatSnapshot) float64 { return float64(s.EasySolved) },
+	"medium_solved":  func(s *models.UserStatSnapshot) float64 { return float64(s.MediumSolved) },
+	"hard_solved":    func(s *models.UserStatSnapshot) float64 { return float64(s.HardSolved) },
+	"ranking":        func(s *models.UserStatSnapshot) float64 { return float64(s.Ranking) },
+	"contest_rating": func(s *models.UserStatSnapshot) float64 { return s.ContestRating },
+	"reputation":     func(s *models.UserStatSnapshot) float64 { return float64(s.Reputation) },
+	"streak":         func(s *models.UserStatSnapshot) float64 { return float64(s.Streak) },
+}
+
+// GetHistory returns the time series of metric for the user between from and to.
+// Zero bounds default to the last 90 days.
+func (s *UserService) GetHistory(ctx context.Context, username, metric string, from, to time.Time) ([]HistoryPoint, error) {
+	value, err := historyMetric(metric)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.UserRepo.GetByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if from.IsZero() {
+		from = to.Add(-defaultHistoryWindow)
+	}
+
+	snapshots, err := s.SnapshotRepo.ListByUser(ctx, user.ID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]HistoryPoint, 0, len(snapshots))
+	for i := range snapshots {
+		points = append(points, HistoryPoint{Timestamp: snapshots[i].CreatedAt, Value: value(&snapshots[i])})
+	}
+	return points, nil
+}
+
+func historyMetric(metric string) (func(*models.UserStatSnapshot) float64, error) {
+	if metric == "" {
+		metric = "total_solved"
+	}
+	if value, ok := HistoryMetrics[metric]; ok {
+		return value, nil
+	}
+
+	if slug, ok := strings.CutPrefix(metric, "topic:"); ok && slug != "" {
+		return func(s *models.UserStatSnapshot) float64 {
+			var counts map[string]int
+			if err := json.Unmarshal(s.TopicCounts, &counts); err != nil {
+				return 0
+			}
+			return float64(counts[slug])
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnknownMetric, metric)
+}
+
+// recordSnapshot stores the user's freshly synced stats. A failure is logged rather than
+// failing the sync, since the user row itself was already updated.
+func (s *UserService) recordSnapshot(ctx context.Context, user *models.User) {
+	snapshot := &models.UserStatSnapshot{
+		UserID:        user.ID,
+		TotalSolved:   user.TotalSolved,
+		EasySolved:    user.EasySolved,
+		MediumSolved:  user.MediumSolved,
+		HardSolved:    user.HardSolved,
+		Ranking:       user.Ranking,
+		ContestRating: user.ContestRating,
+		Reputation:    user.Reputation,
+		Streak:        user.Streak,
+		TopicCounts:   topicCounts(user.SkillTags),
+		CreatedAt:     time.Now(),
+	}
+
+	if err := s.SnapshotRepo.Create(ctx, snapshot); err != nil {
+		log.Printf("Failed to record stat snapshot for %s: %v", user.Username, err)
+	}
+}
+
+// topicCounts maps each SkillTags topic to its solved count
+func topicCounts(skillTags datatypes.JSON) datatypes.JSON {
+	counts := map[string]int{}
+	for slug, tag := range skillTagsBySlug(skillTags) {
+		counts[slug] = tag.ProblemsSolved
+	}
+
+	b, _ := json.Marshal(counts)
+	return datatypes.JSON(b)
+}
@@ -2,8 +2,13 @@ package services
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"strings"
 	"time"
 
 	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/clients/leetcode"
@@ -13,22 +18,59 @@ import (
 )
 
 type UserService struct {
-	UserRepo       repository.UserRepository
-	LeetCodeClient *leetcode.Client
+	UserRepo          repository.UserRepository
+	SnapshotRepo      repository.StatSnapshotRepository
+	SyncEventRepo     repository.SyncEventRepository
+	DailyActivityRepo repository.DailyActivityRepository
+	SolvedRepo        repository.SolvedProblemRepository
+	ActivityRepo      repository.ActivityRepository
+	LeetCodeClient    *leetcode.Client
+
+	listeners []SyncListener
+}
+
+// SyncListener is called after every successful sync, e.g. to update goal progress
+type SyncListener func(ctx context.Context, result *SyncResult) error
+
+// AddSyncListener registers listener. It must be called before the service is used.
+func (s *UserService) AddSyncListener(listener SyncListener) {
+	s.listeners = append(s.listeners, listener)
 }
 
-func NewUserService(userRepo repository.UserRepository, client *leetcode.Client) *UserService {
+func NewUserService(userRepo repository.UserRepository, snapshotRepo repository.StatSnapshotRepository, syncEventRepo repository.SyncEventRepository, dailyActivityRepo repository.DailyActivityRepository, solvedRepo repository.SolvedProblemRepository, activityRepo repository.ActivityRepository, client *leetcode.Client) *UserService {
 	return &UserService{
-		UserRepo:       userRepo,
-		LeetCodeClient: client,
+		UserRepo:          userRepo,
+		SnapshotRepo:      snapshotRepo,
+		SyncEventRepo:     syncEventRepo,
+		DailyActivityRepo: dailyActivityRepo,
+		SolvedRepo:        solvedRepo,
+		ActivityRepo:      activityRepo,
+		LeetCodeClient:    client,
 	}
 }
 
+var (
+	ErrUserNotFound              = errors.New("user not found, sign up before syncing")
+	ErrVerificationNotStarted    = errors.New("no pending verification, request a verification token first")
+	ErrVerificationExpired       = errors.New("verification token expired, request a new one")
+	ErrVerificationTokenNotFound = errors.New("verification token not found in your LeetCode profile about section")
+)
+
+const verificationTokenTTL = 24 * time.Hour
+
+// VerificationChallenge is returned when a user starts proving ownership of their LeetCode account
+type VerificationChallenge struct {
+	Token        string    `json:"token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	Instructions string    `json:"instructions"`
+}
+
 func (s *UserService) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
 	return s.UserRepo.GetByUsername(ctx, username)
 }
 
-func (s *UserService) SyncUser(ctx context.Context, username string) (*models.User, error) {
+// SyncUser refreshes the user from LeetCode and reports what changed since the previous sync
+func (s *UserService) SyncUser(ctx context.Context, username string) (*SyncResult, error) {
 	// 1. Fetch all data from LeetCode concurrently
 	data := s.LeetCodeClient.FetchAllUserData(username)
 
@@ -50,15 +92,13 @@ func (s *UserService) SyncUser(ctx context.Context, username string) (*models.Us
 	}
 
 	if user == nil {
-		// If strictly syncing, maybe we shouldn't create if it doesn't exist?
-		// But the requirement says "if user request for enpoint to sync leetcode current data then i will fetch all data... and update my db"
-		// It implies the user might already exist. If not, we can create.
-		user = &models.User{
-			Username:  username,
-			CreatedAt: time.Now(),
-		}
+		// Only registered users can be synced; creating rows here would let anyone claim a username
+		return nil, ErrUserNotFound
 	}
 
+	// Keep the previous state around to diff against
+	previous := *user
+
 	// 3. Update User fields
 	// Basic Profile
 	if data.Profile != nil {
@@ -126,6 +166,11 @@ func (s *UserService) SyncUser(ctx context.Context, username string) (*models.Us
 		}
 	}
 
+	// Daily activity table and server-side streak (falls back to the upstream streak on failure)
+	if err := s.ingestCalendar(ctx, user); err != nil {
+		log.Printf("Failed to ingest submission calendar for %s: %v", user.Username, err)
+	}
+
 	// Calculate App-Specific Score (Placeholder logic)
 	// TotalScore logic wasn't specified, so I'll leave it or basic sum
 	user.TotalScore = user.TotalSolved * 10
@@ -137,24 +182,135 @@ func (s *UserService) SyncUser(ctx context.Context, username string) (*models.Us
 		user.ScoreRank = "Advanced"
 	}
 
-	user.UpdatedAt = time.Now()
+	now := time.Now()
+	user.UpdatedAt = now
+	user.LastSyncedAt = &now
 
-	// 4. Save to DB
-	if user.ID == (models.User{}.ID) { // Check if new user (empty UUID or nil-like)
-		// Wait, UUID is struct.
-		// Actually, if we fetched by username and it was nil, we created a new struct.
-		// Its ID will be empty.
-		// However, standard flow might be: User should Register first.
-		// But Sync might be called for existing user.
-		// If user doesn't exist in DB, Create.
-		if err := s.UserRepo.Create(ctx, user); err != nil {
-			return nil, err
-		}
-	} else {
-		if err := s.UserRepo.Update(ctx, user); err != nil {
-			return nil, err
+	// 4. Save to DB. Only the synced columns are written: a full save would undo password,
+	// 2FA, role or deletion changes made while the sync was running.
+	if err := s.UserRepo.UpdateColumns(ctx, user.ID, syncedColumns(user)); err != nil {
+		return nil, err
+	}
+
+	// 5. Keep a history of the synced stats, solved problems and what changed
+	s.recordSnapshot(ctx, user)
+
+	if err := s.recordSolvedProblems(ctx, user, data.AcSubmissions); err != nil {
+		log.Printf("Failed to record solved problems for %s: %v", user.Username, err)
+	}
+
+	diff := diffUsers(&previous, user)
+	s.recordSyncEvent(ctx, user, diff)
+
+	result := &SyncResult{User: user, Diff: diff}
+	for _, listener := range s.listeners {
+		if err := listener(ctx, result); err != nil {
+			log.Printf("Sync listener failed for %s: %v", user.Username, err)
 		}
 	}
 
+	return result, nil
+}
+
+// syncedColumns are the user columns SyncUser writes
+func syncedColumns(user *models.User) map[string]interface{} {
+	return map[string]interface{}{
+		"name":                   user.Name,
+		"avatar":                 user.Avatar,
+		"about":                  user.About,
+		"country":                user.Country,
+		"school":                 user.School,
+		"company":                user.Company,
+		"git_hub":                user.GitHub,
+		"twitter":                user.Twitter,
+		"linked_in":              user.LinkedIn,
+		"websites":               user.Websites,
+		"ranking":                user.Ranking,
+		"reputation":             user.Reputation,
+		"contribution_point":     user.ContributionPoint,
+		"total_solved":           user.TotalSolved,
+		"easy_solved":            user.EasySolved,
+		"medium_solved":          user.MediumSolved,
+		"hard_solved":            user.HardSolved,
+		"skill_tags":             user.SkillTags,
+		"contest_rating":         user.ContestRating,
+		"contest_global_ranking": user.ContestGlobalRanking,
+		"contest_top_percentage": user.ContestTopPercentage,
+		"total_participants":     user.TotalParticipants,
+		"contest_attended":       user.ContestAttended,
+		"badges":                 user.Badges,
+		"streak":                 user.Streak,
+		"total_active_days":      user.TotalActiveDays,
+		"active_years":           user.ActiveYears,
+		"submission_calendar":    user.SubmissionCalendar,
+		"total_score":            user.TotalScore,
+		"score_rank":             user.ScoreRank,
+		"updated_at":             user.UpdatedAt,
+		"last_synced_at":         user.LastSyncedAt,
+	}
+}
+
+// StartLeetCodeVerification issues a token the user must paste into their LeetCode "about" section
+func (s *UserService) StartLeetCodeVerification(ctx context.Context, user *models.User) (*VerificationChallenge, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	token := "lct-verify-" + hex.EncodeToString(b)
+	expiresAt := time.Now().Add(verificationTokenTTL)
+
+	user.VerificationToken = token
+	user.VerificationTokenExpiresAt = &expiresAt
+	err := s.UserRepo.UpdateColumns(ctx, user.ID, map[string]interface{}{
+		"verification_token":            token,
+		"verification_token_expires_at": expiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &VerificationChallenge{
+		Token:        token,
+		ExpiresAt:    expiresAt,
+		Instructions: fmt.Sprintf("Add %s anywhere in the About section of your LeetCode profile (%s), then call the confirm endpoint. You can remove it afterwards.", token, user.Username),
+	}, nil
+}
+
+// ConfirmLeetCodeVerification checks the user's public LeetCode profile for the issued token
+// and marks the account as verified when it is found.
+func (s *UserService) ConfirmLeetCodeVerification(ctx context.Context, user *models.User) (*models.User, error) {
+	if user.LeetCodeVerified {
+		return user, nil
+	}
+	if user.VerificationToken == "" || user.VerificationTokenExpiresAt == nil {
+		return nil, ErrVerificationNotStarted
+	}
+	if time.Now().After(*user.VerificationTokenExpiresAt) {
+		return nil, ErrVerificationExpired
+	}
+
+	profile, err := s.LeetCodeClient.GetUserProfile(user.Username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch LeetCode profile: %w", err)
+	}
+	if !strings.Contains(profile.About, user.VerificationToken) {
+		return nil, ErrVerificationTokenNotFound
+	}
+
+	now := time.Now()
+	user.LeetCodeVerified = true
+	user.LeetCodeVerifiedAt = &now
+	user.VerificationToken = ""
+	user.VerificationTokenExpiresAt = nil
+	err = s.UserRepo.UpdateColumns(ctx, user.ID, map[string]interface{}{
+		"leet_code_verified":            true,
+		"leet_code_verified_at":         now,
+		"verification_token":            "",
+		"verification_token_expires_at": nil,
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	return user, nil
 }
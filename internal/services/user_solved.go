@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/clients/leetcode"
+	"github.com/devlpr-nitish/leetcode-tracker-backend/internal/models"
+)
+
+const (
+	defaultSolvedPageSize = 50
+	maxSolvedPageSize     = 200
+)
+
+// SolvedPage is one page of a user's solved problems
+type SolvedPage struct {
+	Problems []models.UserSolvedProblem `json:"problems"`
+	Total    int64                      `json:"total"`
+	Page     int                        `json:"page"`
+	Limit    int                        `json:"limit"`
+}
+
+// GetSolvedProblems lists the problems recorded as solved for the user, most recent first. Pages start at 1.
+func (s *UserService) GetSolvedProblems(ctx context.Context, username string, page, limit int) (*SolvedPage, error) {
+	user, err := s.UserRepo.GetByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = defaultSolvedPageSize
+	}
+	if limit > maxSolvedPageSize {
+		limit = maxSolvedPageSize
+	}
+
+	problems, total, err := s.SolvedRepo.ListByUser(ctx, user.ID, (page-1)*limit, limit)
+	if err != nil {
+		return nil, err
+	}
+	return &SolvedPage{Problems: problems, Total: total, Page: page, Limit: limit}, nil
+}
+
+// recordSolvedProblems stores recent accepted submissions as solved problems and
+// PROBLEM_SOLVED activity. Both are deduplicated, so overlapping syncs are harmless.
+// Malformed submissions are skipped rather than failing the whole batch.
+func (s *UserService) recordSolvedProblems(ctx context.Context, user *models.User, submissions *leetcode.AcSubmissionResponse) error {
+	if submissions == nil || len(submissions.Submission) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	bySlug := map[string]*models.UserSolvedProblem{}
+	var order []string
+	var logs []models.ActivityLog
+	skipped := 0
+
+	for _, sub := range submissions.Submission {
+		ts, err := strconv.ParseInt(sub.Timestamp, 10, 64)
+		if err != nil || sub.TitleSlug == "" {
+			skipped++
+			continue
+		}
+		solvedAt := time.Unix(ts, 0).UTC()
+
+		logs = append(logs, models.ActivityLog{
+			UserID:       user.ID,
+			ActivityType: models.ActivityTypeProblemSolved,
+			ReferenceID:  sub.TitleSlug,
+			Timestamp:    solvedAt,
+		})
+
+		// A problem can be accepted several times in one batch, keep a single row per slug
+		problem, ok := bySlug[sub.TitleSlug]
+		if !ok {
+			bySlug[sub.TitleSlug] = &models.UserSolvedProblem{
+				UserID:        user.ID,
+				TitleSlug:     sub.TitleSlug,
+				Title:         sub.Title,
+				Language:      sub.Lang,
+				FirstSolvedAt: solvedAt,
+				LastSolvedAt:  solvedAt,
+				CreatedAt:     now,
+				UpdatedAt:     now,
+			}
+			order = append(order, sub.TitleSlug)
+			continue
+		}
+		if solvedAt.Before(problem.FirstSolvedAt) {
+			problem.FirstSolvedAt = solvedAt
+		}
+		if solvedAt.After(problem.LastSolvedAt) {
+			problem.LastSolvedAt = solvedAt
+			problem.Language = sub.Lang
+		}
+	}
+
+	if skipped > 0 {
+		log.Printf("Skipped %d malformed accepted submissions for %s", skipped, user.Username)
+	}
+	if len(order) == 0 {
+		return nil
+	}
+
+	problems := make([]models.UserSolvedProblem, 0, len(order))
+	for _, slug := range order {
+		problems = append(problems, *bySlug[slug])
+	}
+
+	if err := s.SolvedRepo.Upsert(ctx, problems); err != nil {
+		return fmt.Errorf("failed to save solved problems: %w", err)
+	}
+	if err := s.ActivityRepo.CreateIgnoringDuplicates(ctx, logs); err != nil {
+		return fmt.Errorf("failed to save activity: %w", err)
+	}
+	return nil
+}
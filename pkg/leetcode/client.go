@@ -162,6 +162,23 @@ type TopicTag struct {
 
 // GetProblems fetches problems from the external API
 func (c *Client) GetProblems(limit int, skip int, tags []string, difficulty string) ([]APIQuestion, error) {
+	parsedResp, err := c.queryProblems(limit, skip, tags, difficulty)
+	if err != nil {
+		return nil, err
+	}
+	return parsedResp.ProblemsetQuestionList, nil
+}
+
+// GetProblemCount returns how many problems match the tags and difficulty
+func (c *Client) GetProblemCount(tags []string, difficulty string) (int, error) {
+	parsedResp, err := c.queryProblems(1, 0, tags, difficulty)
+	if err != nil {
+		return 0, err
+	}
+	return parsedResp.TotalQuestions, nil
+}
+
+func (c *Client) queryProblems(limit int, skip int, tags []string, difficulty string) (*ExternalProblemResponse, error) {
 	// Constuct URL: https://leetcode-api-v8xt.onrender.com/problems
 	baseURL := "https://leetcode-api-v8xt.onrender.com/problems"
 
@@ -207,7 +224,7 @@ func (c *Client) GetProblems(limit int, skip int, tags []string, difficulty stri
 		return nil, err
 	}
 
-	return parsedResp.ProblemsetQuestionList, nil
+	return &parsedResp, nil
 }
 
 func joinTags(tags []string) string {